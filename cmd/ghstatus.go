@@ -16,6 +16,14 @@ import (
 
 var (
 	format string
+
+	pageURL  string
+	pageName string
+
+	cacheDir string
+	cacheTTL time.Duration
+
+	staleOnError bool
 )
 
 var (
@@ -28,7 +36,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.Summary); err != nil {
 				return fmt.Errorf("error getting summary: %w", err)
@@ -47,7 +55,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.Status); err != nil {
 				return fmt.Errorf("error getting status: %w", err)
@@ -66,7 +74,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.Components); err != nil {
 				return fmt.Errorf("error getting components: %w", err)
@@ -90,7 +98,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.UnresolvedIncidents); err != nil {
 				return fmt.Errorf("error getting unresolved: %w", err)
@@ -109,7 +117,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.AllIncidents); err != nil {
 				return fmt.Errorf("error getting all incidents: %w", err)
@@ -133,7 +141,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.UpcomingScheduledMaintenances); err != nil {
 				return fmt.Errorf("error getting upcoming scheduled maintenances: %w", err)
@@ -152,7 +160,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.UpcomingScheduledMaintenances); err != nil {
 				return fmt.Errorf("error getting active scheduled maintenances: %w", err)
@@ -171,7 +179,7 @@ var (
 			if err != nil {
 				return fmt.Errorf("error creating logger: %w", err)
 			}
-			client := ghstatus.NewClient(log)
+			client := ghstatus.NewClient(log, clientOptions()...)
 
 			if err := printResponse(cmd.Context(), client.AllScheduledMaintenances); err != nil {
 				return fmt.Errorf("error getting all scheduled maintenances: %w", err)
@@ -193,18 +201,71 @@ func init() {
 
 func init() {
 	addOutputFlag(summaryCmd)
+	addStaleOnErrorFlag(summaryCmd)
 	addOutputFlag(statusCmd)
+	addStaleOnErrorFlag(statusCmd)
 	addOutputFlag(componentsCmd)
+	addStaleOnErrorFlag(componentsCmd)
 	addOutputFlag(unresolvedIncidentsCmd)
+	addStaleOnErrorFlag(unresolvedIncidentsCmd)
 	addOutputFlag(allIncidentsCmd)
+	addStaleOnErrorFlag(allIncidentsCmd)
 	addOutputFlag(upcomingScheduledMaintenancesCmd)
+	addStaleOnErrorFlag(upcomingScheduledMaintenancesCmd)
 	addOutputFlag(activeScheduledMaintenancesCmd)
+	addStaleOnErrorFlag(activeScheduledMaintenancesCmd)
 	addOutputFlag(allScheduledMaintenancesCmd)
+	addStaleOnErrorFlag(allScheduledMaintenancesCmd)
+}
+
+// addPageFlags will add the flags selecting the status page to the given command.
+func addPageFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&pageURL, "page-url", ghstatus.GithubStatusURL, "The base URL of the Statuspage-hosted status page to query.")
+	cmd.PersistentFlags().StringVar(&pageName, "page-name", ghstatus.GithubPageName, "The name of the status page to query.")
+	cmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "",
+		"A directory to cache responses in so that they're shared across invocations. Responses aren't cached on disk without one.")
+	cmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 30*time.Second,
+		"How long responses cached in --cache-dir are used without asking the status page whether they've changed. The monitor always asks.")
+}
+
+// clientOptions returns the client options derived from the page flags.
+func clientOptions() []ghstatus.ClientOption {
+	options := []ghstatus.ClientOption{
+		ghstatus.WithBaseURL(pageURL),
+		ghstatus.WithPageName(pageName),
+		cacheOption(),
+	}
+	if staleOnError {
+		options = append(options, ghstatus.WithStaleOnError())
+	}
+	return options
+}
+
+// cacheOption returns the client option derived from the cache flags. The TTL only applies to responses
+// cached on disk, since a single invocation doesn't request the same response twice.
+func cacheOption() ghstatus.ClientOption {
+	if cacheDir == "" {
+		return ghstatus.WithCache("", 0)
+	}
+	return ghstatus.WithCache(cacheDir, cacheTTL)
+}
+
+// monitorCacheOption returns the cache option for the monitor's clients. These always ask the status page
+// whether responses have changed so that polls aren't answered from the cache.
+func monitorCacheOption() ghstatus.ClientOption {
+	return ghstatus.WithCache(cacheDir, 0)
+}
+
+// addStaleOnErrorFlag will add the stale on error flag to the given command. The monitor doesn't
+// have it, since it needs to know when the status page can't be reached.
+func addStaleOnErrorFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&staleOnError, "stale-on-error", false,
+		"Show the last fetched response, marked as stale, if the status page can't be reached. Use with --cache-dir to keep responses across invocations.")
 }
 
 // addOutputFlag will add the output flag to the given command.
 func addOutputFlag(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (valid values are [yaml, json, table])")
+	cmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (valid values are [yaml, json, table, tree]). The tree format is only supported for the summary and components.")
 }
 
 // printResponse will get an object and then print the response for the object.
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus/render"
+	"github.com/mdwn/ghstatus/pkg/journal"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyJournal   string
+	historySince     string
+	historyUntil     string
+	historyPage      string
+	historyComponent string
+	historyIncident  string
+	historyKinds     []string
+
+	historyCmd = &cobra.Command{
+		Use:   "history",
+		Short: "Queries the change journal written by the monitor",
+		Long: "History queries the change journal written by the monitor when run with --journal.\n\n" +
+			"Times can be given either as RFC3339 timestamps or as durations relative to now, e.g. --since 168h.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if historyJournal == "" {
+				return errors.New("no journal given")
+			}
+
+			format, err := render.FormatFromString(format)
+			if err != nil {
+				return fmt.Errorf("error getting format from string: %w", err)
+			}
+
+			now := time.Now()
+			query := journal.Query{
+				Page:      historyPage,
+				Component: historyComponent,
+				Incident:  historyIncident,
+			}
+			if query.Since, err = parseHistoryTime(now, historySince); err != nil {
+				return fmt.Errorf("error parsing since: %w", err)
+			}
+			if query.Until, err = parseHistoryTime(now, historyUntil); err != nil {
+				return fmt.Errorf("error parsing until: %w", err)
+			}
+			for _, kind := range historyKinds {
+				k, err := journal.KindFromString(kind)
+				if err != nil {
+					return err
+				}
+				query.Kinds = append(query.Kinds, k)
+			}
+
+			entries, err := journal.Read(historyJournal, query)
+			if err != nil {
+				return fmt.Errorf("error reading journal: %w", err)
+			}
+
+			out, err := render.Render(entries, format)
+			if err != nil {
+				return fmt.Errorf("error rendering history: %w", err)
+			}
+
+			fmt.Print(out)
+
+			return nil
+		},
+	}
+)
+
+func init() {
+	historyCmd.Flags().StringVarP(&historyJournal, "journal", "j", "", "The journal file written by the monitor.")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only show changes at or after this time.")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "Only show changes before this time.")
+	historyCmd.Flags().StringVar(&historyPage, "page", "", "Only show changes to the status page with this name.")
+	historyCmd.Flags().StringVar(&historyComponent, "component", "", "Only show changes to the component with this name or ID, and the incidents and scheduled maintenances affecting it.")
+	historyCmd.Flags().StringVar(&historyIncident, "incident", "", "Only show changes to the incident with this name or ID.")
+	historyCmd.Flags().StringSliceVar(&historyKinds, "kind", nil, "Only show changes of these kinds (valid values are [status, component, incident, scheduled_maintenance or maintenance, source]).")
+	addOutputFlag(historyCmd)
+}
+
+// parseHistoryTime parses either an RFC3339 timestamp or a duration before now.
+func parseHistoryTime(now time.Time, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -3,21 +3,119 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/delivery"
+	"github.com/mdwn/ghstatus/pkg/escalation"
+	"github.com/mdwn/ghstatus/pkg/filter"
 	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/journal"
 	"github.com/mdwn/ghstatus/pkg/logging"
 	"github.com/mdwn/ghstatus/pkg/monitor"
 	"github.com/mdwn/ghstatus/pkg/notifiers"
+	"github.com/mdwn/ghstatus/pkg/quiet"
+	"github.com/mdwn/ghstatus/pkg/router"
+	"github.com/mdwn/ghstatus/pkg/state"
+	"github.com/ory/viper"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
+const (
+	monitorIncludeComponentsCfg  = "monitor.include_components"
+	monitorIncludeComponentsFlag = "include-components"
+	monitorIncludeComponentsEnv  = "MONITOR_INCLUDE_COMPONENTS"
+
+	monitorExcludeComponentsCfg  = "monitor.exclude_components"
+	monitorExcludeComponentsFlag = "exclude-components"
+	monitorExcludeComponentsEnv  = "MONITOR_EXCLUDE_COMPONENTS"
+
+	monitorMinSeverityCfg  = "monitor.min_severity"
+	monitorMinSeverityFlag = "min-severity"
+	monitorMinSeverityEnv  = "MONITOR_MIN_SEVERITY"
+
+	monitorStabilizePollsCfg  = "monitor.stabilize.polls"
+	monitorStabilizePollsFlag = "stabilize-polls"
+	monitorStabilizePollsEnv  = "MONITOR_STABILIZE_POLLS"
+
+	monitorStabilizeDurationCfg  = "monitor.stabilize.duration"
+	monitorStabilizeDurationFlag = "stabilize-duration"
+	monitorStabilizeDurationEnv  = "MONITOR_STABILIZE_DURATION"
+
+	monitorFlapThresholdCfg  = "monitor.flap.threshold"
+	monitorFlapThresholdFlag = "flap-threshold"
+	monitorFlapThresholdEnv  = "MONITOR_FLAP_THRESHOLD"
+
+	monitorFlapWindowCfg  = "monitor.flap.window"
+	monitorFlapWindowFlag = "flap-window"
+	monitorFlapWindowEnv  = "MONITOR_FLAP_WINDOW"
+
+	monitorDigestIntervalCfg  = "monitor.digest_interval"
+	monitorDigestIntervalFlag = "digest-interval"
+	monitorDigestIntervalEnv  = "MONITOR_DIGEST_INTERVAL"
+
+	monitorDeadLetterCfg  = "monitor.dead_letter"
+	monitorDeadLetterFlag = "dead-letter"
+	monitorDeadLetterEnv  = "MONITOR_DEAD_LETTER"
+
+	monitorSpoolDirCfg  = "monitor.spool_dir"
+	monitorSpoolDirFlag = "spool-dir"
+	monitorSpoolDirEnv  = "MONITOR_SPOOL_DIR"
+
+	monitorPollIntervalCfg  = "monitor.poll_interval"
+	monitorPollIntervalFlag = "poll-interval"
+	monitorPollIntervalEnv  = "MONITOR_POLL_INTERVAL"
+
+	monitorPollActiveIntervalCfg  = "monitor.poll_active_interval"
+	monitorPollActiveIntervalFlag = "poll-active-interval"
+	monitorPollActiveIntervalEnv  = "MONITOR_POLL_ACTIVE_INTERVAL"
+
+	monitorPollMaxIntervalCfg  = "monitor.poll_max_interval"
+	monitorPollMaxIntervalFlag = "poll-max-interval"
+	monitorPollMaxIntervalEnv  = "MONITOR_POLL_MAX_INTERVAL"
+
+	monitorPollJitterCfg  = "monitor.poll_jitter"
+	monitorPollJitterFlag = "poll-jitter"
+	monitorPollJitterEnv  = "MONITOR_POLL_JITTER"
+
+	monitorUnavailableAfterCfg  = "monitor.unavailable_after"
+	monitorUnavailableAfterFlag = "unavailable-after"
+	monitorUnavailableAfterEnv  = "MONITOR_UNAVAILABLE_AFTER"
+
+	monitorNotifyTimeoutCfg  = "monitor.notify_timeout"
+	monitorNotifyTimeoutFlag = "notify-timeout"
+	monitorNotifyTimeoutEnv  = "MONITOR_NOTIFY_TIMEOUT"
+
+	monitorRetryInitialBackoffCfg  = "monitor.retry.initial_backoff"
+	monitorRetryInitialBackoffFlag = "retry-initial-backoff"
+	monitorRetryInitialBackoffEnv  = "MONITOR_RETRY_INITIAL_BACKOFF"
+
+	monitorRetryMaxBackoffCfg  = "monitor.retry.max_backoff"
+	monitorRetryMaxBackoffFlag = "retry-max-backoff"
+	monitorRetryMaxBackoffEnv  = "MONITOR_RETRY_MAX_BACKOFF"
+
+	monitorRetryMaxAgeCfg  = "monitor.retry.max_age"
+	monitorRetryMaxAgeFlag = "retry-max-age"
+	monitorRetryMaxAgeEnv  = "MONITOR_RETRY_MAX_AGE"
+
+	monitorRoutesCfg      = "routes"
+	monitorQuietHoursCfg  = "quiet_hours"
+	monitorMutesCfg       = "mutes"
+	monitorDigestsCfg     = "digests"
+	monitorEscalationsCfg = "escalations"
+)
+
 var (
 	monitorNotifiers        []string
 	monitorNotifyOnFirstRun bool
+	monitorPages            []string
+	monitorStateFile        string
+	monitorJournal          string
 
 	monitorCmd = &cobra.Command{
 		Use:   "monitor",
@@ -26,6 +124,8 @@ var (
 			builder := strings.Builder{}
 
 			builder.WriteString("Monitor will monitor the Github Status and report changes to the configued notifiers.\n\n")
+			builder.WriteString("Other Statuspage-hosted pages can be monitored alongside or instead of Github by\n")
+			builder.WriteString("passing --pages name=url, e.g. --pages github=https://www.githubstatus.com,npm=https://status.npmjs.org\n\n")
 			builder.WriteString("Available notifiers:\n")
 			for _, name := range notifiers.ListNotifiers() {
 				builder.WriteString(fmt.Sprintf(" - %s\n", name))
@@ -41,20 +141,154 @@ var (
 				return fmt.Errorf("error creating logger: %w", err)
 			}
 
-			client := ghstatus.NewClient(log)
+			clients, err := monitorClients(log)
+			if err != nil {
+				return err
+			}
 			clock := clockwork.NewRealClock()
 
-			if len(monitorNotifiers) == 0 {
+			var opts []monitor.Option
+			filter, err := monitorFilter()
+			if err != nil {
+				return err
+			}
+			if filter != nil {
+				opts = append(opts, monitor.WithFilter(filter))
+			}
+
+			stabilization := monitor.Stabilization{
+				Polls:         viper.GetInt(monitorStabilizePollsCfg),
+				Duration:      viper.GetDuration(monitorStabilizeDurationCfg),
+				FlapThreshold: viper.GetInt(monitorFlapThresholdCfg),
+				FlapWindow:    viper.GetDuration(monitorFlapWindowCfg),
+			}
+			if stabilization.FlapThreshold > 0 && stabilization.FlapWindow <= 0 {
+				return errors.New("the flap window must be positive when a flap threshold is set")
+			}
+			opts = append(opts, monitor.WithStabilization(stabilization))
+
+			quietWindows, err := monitorQuietWindows()
+			if err != nil {
+				return err
+			}
+			if quietWindows != nil {
+				opts = append(opts, monitor.WithQuietWindows(quietWindows))
+			}
+
+			digests, err := monitorDigests()
+			if err != nil {
+				return err
+			}
+			if len(digests) > 0 {
+				opts = append(opts, monitor.WithDigests(digests...))
+			}
+
+			escalations, err := monitorEscalations()
+			if err != nil {
+				return err
+			}
+			if escalations != nil {
+				opts = append(opts, monitor.WithEscalations(escalations))
+			}
+
+			notifierNames := monitorNotifiers
+			router, err := monitorRouter()
+			if err != nil {
+				return err
+			}
+			if router == nil && escalations != nil && len(withNotifiers(notifierNames, escalations.Notifiers())) > len(notifierNames) {
+				// Notifiers only named by escalations shouldn't receive every change, so route everything
+				// to the notifiers that have been enabled explicitly.
+				router, err = broadcastRouter(notifierNames)
+				if err != nil {
+					return err
+				}
+			}
+			if router != nil {
+				opts = append(opts, monitor.WithRouter(router))
+				notifierNames = withNotifiers(notifierNames, router.Notifiers())
+			}
+			if escalations != nil {
+				notifierNames = withNotifiers(notifierNames, escalations.Notifiers())
+			}
+
+			if len(notifierNames) == 0 {
 				return errors.New("no notifiers configured")
 			}
+			if monitorStateFile != "" {
+				opts = append(opts, monitor.WithStateStore(state.NewFileStore(monitorStateFile)))
+			}
+			if monitorJournal != "" {
+				j, err := journal.Open(monitorJournal)
+				if err != nil {
+					return err
+				}
+				defer j.Close()
+				opts = append(opts, monitor.WithJournal(j))
+			}
+
+			pollInterval := viper.GetDuration(monitorPollIntervalCfg)
+			if pollInterval <= 0 {
+				return errors.New("the poll interval must be positive")
+			}
+			polling := monitor.Polling{
+				ActiveInterval: viper.GetDuration(monitorPollActiveIntervalCfg),
+				MaxInterval:    viper.GetDuration(monitorPollMaxIntervalCfg),
+				Jitter:         viper.GetFloat64(monitorPollJitterCfg),
+			}
+			if polling.ActiveInterval < 0 || polling.MaxInterval < 0 {
+				return errors.New("poll intervals can't be negative")
+			}
+			if polling.Jitter < 0 || polling.Jitter >= 1 {
+				return errors.New("the poll jitter must be at least 0 and less than 1")
+			}
+			opts = append(opts, monitor.WithPolling(polling))
+
+			opts = append(opts, monitor.WithUnavailableAfter(viper.GetInt(monitorUnavailableAfterCfg)))
+			opts = append(opts, monitor.WithNotifyTimeout(viper.GetDuration(monitorNotifyTimeoutCfg)))
+
+			monitor := monitor.New(log, clock, monitorNotifyOnFirstRun, opts...)
+
+			for _, client := range clients {
+				if err := monitor.RegisterClient(client); err != nil {
+					return fmt.Errorf("error registering status page %s: %w", ghstatus.PageName(client), err)
+				}
+			}
 
-			monitor := monitor.New(log, clock, client, monitorNotifyOnFirstRun)
+			queueConfig := delivery.Config{
+				InitialBackoff: viper.GetDuration(monitorRetryInitialBackoffCfg),
+				MaxBackoff:     viper.GetDuration(monitorRetryMaxBackoffCfg),
+				MaxAge:         viper.GetDuration(monitorRetryMaxAgeCfg),
+				Timeout:        viper.GetDuration(monitorNotifyTimeoutCfg),
+			}
+			if deadLetterFile := viper.GetString(monitorDeadLetterCfg); deadLetterFile != "" {
+				deadLetters, err := delivery.OpenDeadLetters(deadLetterFile)
+				if err != nil {
+					return err
+				}
+				defer deadLetters.Close()
+				queueConfig.DeadLetters = deadLetters
+			}
+			spoolDir := viper.GetString(monitorSpoolDirCfg)
+			if spoolDir != "" {
+				if err := os.MkdirAll(spoolDir, 0700); err != nil {
+					return fmt.Errorf("error creating spool directory: %w", err)
+				}
+			}
 
-			for _, name := range monitorNotifiers {
-				notifier, err := notifiers.GetNotifier(log, name)
+			for _, name := range notifierNames {
+				next, err := notifiers.GetNotifier(log, name)
 				if err != nil {
 					return fmt.Errorf("error creating notifier %s: %w", name, err)
 				}
+				config := queueConfig
+				if spoolDir != "" {
+					config.Spool = filepath.Join(spoolDir, name+".json")
+				}
+				notifier, err := delivery.NewQueue(log, clock, next, config)
+				if err != nil {
+					return fmt.Errorf("error creating delivery queue for %s: %w", name, err)
+				}
 				defer func() {
 					if err := notifier.Cleanup(); err != nil {
 						log.With(zap.Error(err), zap.String("notifier", notifier.Name())).Error("error cleaning up")
@@ -65,7 +299,7 @@ var (
 				}
 			}
 
-			monitor.MonitorAndNotify(ctx, time.Minute)
+			monitor.MonitorAndNotify(ctx, pollInterval)
 
 			return nil
 		},
@@ -74,6 +308,233 @@ var (
 
 func init() {
 	monitorCmd.Flags().StringSliceVarP(&monitorNotifiers, "notifiers", "n", []string{notifiers.Stdout}, "The notifiers to use for the monitor.")
+	monitorCmd.Flags().StringSliceVarP(&monitorPages, "pages", "p", nil, "The status pages to monitor as name=url pairs. Defaults to the page given by --page-url.")
+	monitorCmd.Flags().StringVar(&monitorStateFile, "state-file", "", "A JSON file to persist monitor state to across restarts.")
+	monitorCmd.Flags().StringVar(&monitorJournal, "journal", "", "A file to append every detected change to as JSON lines. Query it with the history command.")
 	monitorCmd.Flags().BoolVarP(&monitorNotifyOnFirstRun, "notify-on-first-run", "f", false, "Whether the monitor should send notifications on the first run.")
+	monitorCmd.Flags().StringSlice(monitorIncludeComponentsFlag, nil,
+		"Only notify about these components. Names, globs and regular expressions prefixed with re: are accepted.")
+	monitorCmd.Flags().StringSlice(monitorExcludeComponentsFlag, nil,
+		"Never notify about these components. Names, globs and regular expressions prefixed with re: are accepted.")
+	monitorCmd.Flags().String(monitorMinSeverityFlag, "",
+		"Only notify about changes at least this severe, e.g. minor, major, critical or a component status such as partial_outage.")
+	monitorCmd.Flags().Int(monitorStabilizePollsFlag, 0,
+		"Only report a component status change once it has been seen in this many consecutive polls.")
+	monitorCmd.Flags().Duration(monitorStabilizeDurationFlag, 0,
+		"Only report a component status change once it has persisted for this long.")
+	monitorCmd.Flags().Int(monitorFlapThresholdFlag, 0,
+		"Report a component as unstable once its status has changed this many times within the flap window. Zero disables flap detection.")
+	monitorCmd.Flags().Duration(monitorFlapWindowFlag, 30*time.Minute,
+		"The window status changes are counted in for flap detection.")
+	monitorCmd.Flags().Duration(monitorDigestIntervalFlag, 0,
+		"Collect changes for this long and deliver them to every notifier as a single summary. Zero delivers changes as they're found.")
+	monitorCmd.Flags().String(monitorDeadLetterFlag, "",
+		"A file to append notifications that couldn't be delivered to as JSON lines. Resend them with notifiers redeliver.")
+	monitorCmd.Flags().String(monitorSpoolDirFlag, "",
+		"A directory to keep notifications waiting to be delivered in, so that they're delivered after a restart or crash.")
+	monitorCmd.Flags().Duration(monitorPollIntervalFlag, time.Minute, "The time between polls of each status page.")
+	monitorCmd.Flags().Duration(monitorPollActiveIntervalFlag, 0,
+		"The time between polls while something is wrong on a status page. Zero polls at the usual interval.")
+	monitorCmd.Flags().Duration(monitorPollMaxIntervalFlag, 0,
+		"Double the time between polls while everything is operational, up to this. Zero doesn't back off.")
+	monitorCmd.Flags().Float64(monitorPollJitterFlag, 0.1,
+		"The fraction of the time between polls to randomly add or subtract, so that replicas don't poll in lockstep.")
+	monitorCmd.Flags().Int(monitorUnavailableAfterFlag, 3,
+		"Notify that a status page is unavailable once polling it has failed this many times in a row. Zero never notifies.")
+	monitorCmd.Flags().Duration(monitorNotifyTimeoutFlag, monitor.DefaultNotifyTimeout,
+		"How long each notifier is given to deliver a notification. Zero doesn't time out.")
+	monitorCmd.Flags().Duration(monitorRetryInitialBackoffFlag, delivery.DefaultInitialBackoff,
+		"How long to wait before retrying a failed notification. The wait doubles with every retry.")
+	monitorCmd.Flags().Duration(monitorRetryMaxBackoffFlag, delivery.DefaultMaxBackoff,
+		"The longest wait between retries of a failed notification.")
+	monitorCmd.Flags().Duration(monitorRetryMaxAgeFlag, delivery.DefaultMaxAge,
+		"How long to retry a failed notification for before giving up on it. Zero doesn't retry.")
 	notifiers.RegisterCommandFlags(monitorCmd)
+
+	err := multierror.Append(nil,
+		viper.BindPFlag(monitorIncludeComponentsCfg, monitorCmd.Flags().Lookup(monitorIncludeComponentsFlag)),
+		viper.BindEnv(monitorIncludeComponentsCfg, monitorIncludeComponentsEnv),
+
+		viper.BindPFlag(monitorExcludeComponentsCfg, monitorCmd.Flags().Lookup(monitorExcludeComponentsFlag)),
+		viper.BindEnv(monitorExcludeComponentsCfg, monitorExcludeComponentsEnv),
+
+		viper.BindPFlag(monitorMinSeverityCfg, monitorCmd.Flags().Lookup(monitorMinSeverityFlag)),
+		viper.BindEnv(monitorMinSeverityCfg, monitorMinSeverityEnv),
+
+		viper.BindPFlag(monitorStabilizePollsCfg, monitorCmd.Flags().Lookup(monitorStabilizePollsFlag)),
+		viper.BindEnv(monitorStabilizePollsCfg, monitorStabilizePollsEnv),
+
+		viper.BindPFlag(monitorStabilizeDurationCfg, monitorCmd.Flags().Lookup(monitorStabilizeDurationFlag)),
+		viper.BindEnv(monitorStabilizeDurationCfg, monitorStabilizeDurationEnv),
+
+		viper.BindPFlag(monitorFlapThresholdCfg, monitorCmd.Flags().Lookup(monitorFlapThresholdFlag)),
+		viper.BindEnv(monitorFlapThresholdCfg, monitorFlapThresholdEnv),
+
+		viper.BindPFlag(monitorFlapWindowCfg, monitorCmd.Flags().Lookup(monitorFlapWindowFlag)),
+		viper.BindEnv(monitorFlapWindowCfg, monitorFlapWindowEnv),
+
+		viper.BindPFlag(monitorDigestIntervalCfg, monitorCmd.Flags().Lookup(monitorDigestIntervalFlag)),
+		viper.BindEnv(monitorDigestIntervalCfg, monitorDigestIntervalEnv),
+
+		viper.BindPFlag(monitorDeadLetterCfg, monitorCmd.Flags().Lookup(monitorDeadLetterFlag)),
+		viper.BindEnv(monitorDeadLetterCfg, monitorDeadLetterEnv),
+
+		viper.BindPFlag(monitorSpoolDirCfg, monitorCmd.Flags().Lookup(monitorSpoolDirFlag)),
+		viper.BindEnv(monitorSpoolDirCfg, monitorSpoolDirEnv),
+
+		viper.BindPFlag(monitorPollIntervalCfg, monitorCmd.Flags().Lookup(monitorPollIntervalFlag)),
+		viper.BindEnv(monitorPollIntervalCfg, monitorPollIntervalEnv),
+
+		viper.BindPFlag(monitorPollActiveIntervalCfg, monitorCmd.Flags().Lookup(monitorPollActiveIntervalFlag)),
+		viper.BindEnv(monitorPollActiveIntervalCfg, monitorPollActiveIntervalEnv),
+
+		viper.BindPFlag(monitorPollMaxIntervalCfg, monitorCmd.Flags().Lookup(monitorPollMaxIntervalFlag)),
+		viper.BindEnv(monitorPollMaxIntervalCfg, monitorPollMaxIntervalEnv),
+
+		viper.BindPFlag(monitorPollJitterCfg, monitorCmd.Flags().Lookup(monitorPollJitterFlag)),
+		viper.BindEnv(monitorPollJitterCfg, monitorPollJitterEnv),
+
+		viper.BindPFlag(monitorUnavailableAfterCfg, monitorCmd.Flags().Lookup(monitorUnavailableAfterFlag)),
+		viper.BindEnv(monitorUnavailableAfterCfg, monitorUnavailableAfterEnv),
+
+		viper.BindPFlag(monitorNotifyTimeoutCfg, monitorCmd.Flags().Lookup(monitorNotifyTimeoutFlag)),
+		viper.BindEnv(monitorNotifyTimeoutCfg, monitorNotifyTimeoutEnv),
+
+		viper.BindPFlag(monitorRetryInitialBackoffCfg, monitorCmd.Flags().Lookup(monitorRetryInitialBackoffFlag)),
+		viper.BindEnv(monitorRetryInitialBackoffCfg, monitorRetryInitialBackoffEnv),
+
+		viper.BindPFlag(monitorRetryMaxBackoffCfg, monitorCmd.Flags().Lookup(monitorRetryMaxBackoffFlag)),
+		viper.BindEnv(monitorRetryMaxBackoffCfg, monitorRetryMaxBackoffEnv),
+
+		viper.BindPFlag(monitorRetryMaxAgeCfg, monitorCmd.Flags().Lookup(monitorRetryMaxAgeFlag)),
+		viper.BindEnv(monitorRetryMaxAgeCfg, monitorRetryMaxAgeEnv),
+	)
+
+	if err.ErrorOrNil() != nil {
+		panic(fmt.Sprintf("error binding monitor configs: %v", err))
+	}
+}
+
+// monitorRouter returns the router built from the routes in the config file, or nil if there aren't any.
+func monitorRouter() (*router.Router, error) {
+	var rules []router.Rule
+	if err := viper.UnmarshalKey(monitorRoutesCfg, &rules); err != nil {
+		return nil, fmt.Errorf("error reading routes: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return router.New(rules)
+}
+
+// monitorQuietWindows returns the quiet hours and mute windows from the config file, or nil if there aren't any.
+func monitorQuietWindows() (*quiet.Windows, error) {
+	var schedules []quiet.Schedule
+	if err := viper.UnmarshalKey(monitorQuietHoursCfg, &schedules); err != nil {
+		return nil, fmt.Errorf("error reading quiet hours: %w", err)
+	}
+	var mutes []quiet.Mute
+	if err := viper.UnmarshalKey(monitorMutesCfg, &mutes); err != nil {
+		return nil, fmt.Errorf("error reading mutes: %w", err)
+	}
+	if len(schedules) == 0 && len(mutes) == 0 {
+		return nil, nil
+	}
+	return quiet.New(schedules, mutes)
+}
+
+// monitorEscalations returns the escalation policies from the config file, or nil if there aren't any.
+func monitorEscalations() (*escalation.Policies, error) {
+	var policies []escalation.Policy
+	if err := viper.UnmarshalKey(monitorEscalationsCfg, &policies); err != nil {
+		return nil, fmt.Errorf("error reading escalations: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return escalation.New(policies)
+}
+
+// monitorDigests returns the digests from the config file along with the one for every notifier given by
+// --digest-interval, if any.
+func monitorDigests() ([]monitor.Digest, error) {
+	var digests []monitor.Digest
+	if err := viper.UnmarshalKey(monitorDigestsCfg, &digests); err != nil {
+		return nil, fmt.Errorf("error reading digests: %w", err)
+	}
+	for i, digest := range digests {
+		if digest.Interval <= 0 {
+			return nil, fmt.Errorf("digest #%d has no interval", i+1)
+		}
+	}
+	if interval := viper.GetDuration(monitorDigestIntervalCfg); interval > 0 {
+		digests = append(digests, monitor.Digest{Interval: interval})
+	}
+	return digests, nil
+}
+
+// broadcastRouter returns a router that routes every change to the given notifiers.
+func broadcastRouter(names []string) (*router.Router, error) {
+	if len(names) == 0 {
+		return router.New(nil)
+	}
+	return router.New([]router.Rule{{Name: "default", Notifiers: names}})
+}
+
+// withNotifiers adds the notifiers referenced by routes or escalations, if they're not already in the list.
+func withNotifiers(names []string, referenced []string) []string {
+	names = append([]string(nil), names...)
+	enabled := map[string]struct{}{}
+	for _, name := range names {
+		enabled[name] = struct{}{}
+	}
+
+	for _, name := range referenced {
+		if _, ok := enabled[name]; !ok {
+			names = append(names, name)
+			enabled[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// monitorFilter returns the filter for changes to notify about, or nil if no filtering has been configured.
+func monitorFilter() (*filter.Filter, error) {
+	include := viper.GetStringSlice(monitorIncludeComponentsCfg)
+	exclude := viper.GetStringSlice(monitorExcludeComponentsCfg)
+	minSeverityName := viper.GetString(monitorMinSeverityCfg)
+	if len(include) == 0 && len(exclude) == 0 && minSeverityName == "" {
+		return nil, nil
+	}
+
+	minSeverity := ghstatus.SeverityUnknown
+	if minSeverityName != "" {
+		var err error
+		minSeverity, err = ghstatus.ParseSeverity(minSeverityName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filter.New(include, exclude, minSeverity)
+}
+
+// monitorClients returns a client for each page to monitor. If no pages have been given,
+// the page described by the --page-url and --page-name flags is used.
+func monitorClients(log *zap.Logger) ([]ghstatus.Client, error) {
+	if len(monitorPages) == 0 {
+		return []ghstatus.Client{ghstatus.NewClient(log,
+			ghstatus.WithBaseURL(pageURL), ghstatus.WithPageName(pageName), monitorCacheOption())}, nil
+	}
+
+	clients := make([]ghstatus.Client, 0, len(monitorPages))
+	for _, page := range monitorPages {
+		name, url, ok := strings.Cut(page, "=")
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("invalid page %q, expected name=url", page)
+		}
+		clients = append(clients, ghstatus.NewClient(log, ghstatus.WithPageName(name), ghstatus.WithBaseURL(url), monitorCacheOption()))
+	}
+
+	return clients, nil
 }
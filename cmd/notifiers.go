@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/delivery"
+	"github.com/mdwn/ghstatus/pkg/logging"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/mdwn/ghstatus/pkg/notifiers"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	redeliverDeadLetter string
+	redeliverNotifiers  []string
+
+	notifiersCmd = &cobra.Command{
+		Use:   "notifiers",
+		Short: "Manages the notifiers used by the monitor",
+	}
+
+	redeliverCmd = &cobra.Command{
+		Use:   "redeliver",
+		Short: "Resends notifications from a dead-letter file",
+		Long: "Redeliver resends the notifications the monitor gave up on and wrote to the file given by --dead-letter.\n\n" +
+			"Notifications are resent in order, to the notifier they were meant for. Delivered notifications are removed from\n" +
+			"the file and the rest are kept. The monitor can keep running and appending to the file in the meantime.",
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if redeliverDeadLetter == "" {
+				return errors.New("no dead-letter file given")
+			}
+
+			log, err := logging.NewLogger()
+			if err != nil {
+				return fmt.Errorf("error creating logger: %w", err)
+			}
+
+			letters, err := delivery.ReadDeadLetters(redeliverDeadLetter)
+			if err != nil {
+				return err
+			}
+
+			selected := map[string]struct{}{}
+			for _, name := range redeliverNotifiers {
+				selected[name] = struct{}{}
+			}
+
+			created := map[string]notifier.Notifier{}
+			defer func() {
+				for name, notifier := range created {
+					if err := notifier.Cleanup(); err != nil {
+						log.With(zap.Error(err), zap.String("notifier", name)).Error("error cleaning up")
+					}
+				}
+			}()
+
+			var remaining []delivery.DeadLetter
+			var errs []error
+			failed := map[string]struct{}{}
+			delivered := 0
+			for _, letter := range letters {
+				_, isSelected := selected[letter.Notifier]
+				_, hasFailed := failed[letter.Notifier]
+				if (len(selected) > 0 && !isSelected) || hasFailed {
+					// Later notifications for a notifier that failed are kept back so that they stay in order.
+					remaining = append(remaining, letter)
+					continue
+				}
+
+				if err := redeliver(cmd.Context(), log, created, letter); err != nil {
+					letter.Time = time.Now().UTC()
+					letter.Attempts++
+					letter.Error = err.Error()
+					remaining = append(remaining, letter)
+					failed[letter.Notifier] = struct{}{}
+					errs = append(errs, fmt.Errorf("error redelivering to %s: %w", letter.Notifier, err))
+					continue
+				}
+				delivered++
+			}
+
+			// The monitor may have appended dead letters while these were being redelivered, so only the ones
+			// that were read are replaced.
+			if err := delivery.ReplaceDeadLetters(redeliverDeadLetter, len(letters), remaining); err != nil {
+				return err
+			}
+
+			fmt.Printf("Redelivered %d notifications, %d left.\n", delivered, len(remaining))
+			return errors.Join(errs...)
+		},
+	}
+)
+
+// redeliver sends the dead letter to the notifier it was meant for, creating the notifier if it hasn't been
+// created yet.
+func redeliver(ctx context.Context, log *zap.Logger, created map[string]notifier.Notifier, letter delivery.DeadLetter) error {
+	n, ok := created[letter.Notifier]
+	if !ok {
+		var err error
+		n, err = notifiers.GetNotifier(log, letter.Notifier)
+		if err != nil {
+			return err
+		}
+		created[letter.Notifier] = n
+	}
+	return n.Notify(ctx, letter.Message)
+}
+
+func init() {
+	notifiersCmd.AddCommand(redeliverCmd)
+
+	redeliverCmd.Flags().StringVar(&redeliverDeadLetter, "dead-letter", "", "The dead-letter file written by the monitor.")
+	redeliverCmd.Flags().StringSliceVarP(&redeliverNotifiers, "notifiers", "n", nil,
+		"Only redeliver notifications meant for these notifiers.")
+	notifiers.RegisterCommandFlags(redeliverCmd)
+}
@@ -5,15 +5,25 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/ory/viper"
 	"github.com/spf13/cobra"
 )
 
-var rootCmd = &cobra.Command{
-	Use:   "ghstatus",
-	Short: "A tool for querying and monitoring the Github Status API",
-	Long: "ghstatus provides utilities for manually querying and " +
-		"monitoring Github's status using the Github Status API.",
-}
+var (
+	configFile string
+
+	rootCmd = &cobra.Command{
+		Use:   "ghstatus",
+		Short: "A tool for querying and monitoring the Github Status API",
+		Long: "ghstatus provides utilities for manually querying and " +
+			"monitoring Github's status using the Github Status API. Any other " +
+			"Statuspage-hosted status page can be queried by using --page-url.",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return readConfig()
+		},
+	}
+)
 
 func init() {
 	rootCmd.AddCommand(summaryCmd)
@@ -22,11 +32,39 @@ func init() {
 	rootCmd.AddCommand(incidentsCmd)
 	rootCmd.AddCommand(scheduledMaintenancesCmd)
 	rootCmd.AddCommand(monitorCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(notifiersCmd)
+
+	addPageFlags(rootCmd)
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "",
+		"A config file (YAML, JSON or TOML) to read settings from. Flags and environment variables take precedence.")
+}
+
+// readConfig reads the config file, if one has been given.
+func readConfig() error {
+	if configFile == "" {
+		return nil
+	}
+
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	return nil
 }
 
+const (
+	// exitTemporaryFailure is the exit code used when the status API failed in a way that may succeed
+	// when retried later, following EX_TEMPFAIL from sysexits.h.
+	exitTemporaryFailure = 75
+)
+
 func Execute() {
 	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		if ghstatus.Temporary(err) {
+			os.Exit(exitTemporaryFailure)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,188 @@
+package delivery
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// DeadLetter is a message that couldn't be delivered.
+type DeadLetter struct {
+	// Time is when the message was given up on.
+	Time time.Time `json:"time" yaml:"time"`
+
+	// Notifier is the name of the notifier the message couldn't be delivered to.
+	Notifier string `json:"notifier" yaml:"notifier"`
+
+	// Queued is when the message was first queued for delivery.
+	Queued time.Time `json:"queued" yaml:"queued"`
+
+	// Attempts is the number of delivery attempts that were made.
+	Attempts int `json:"attempts" yaml:"attempts"`
+
+	// Error is the error of the last delivery attempt.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// Message is the message that couldn't be delivered.
+	Message notifier.Message `json:"message" yaml:"message"`
+}
+
+// DeadLetters is an append-only file of dead letters stored as JSON lines. The file is locked and reopened
+// for every append so that redelivering from it while the monitor is running doesn't lose dead letters.
+type DeadLetters struct {
+	mu   sync.Mutex
+	path string
+}
+
+// OpenDeadLetters opens the dead-letter file at the given path for appending, creating it if necessary.
+func OpenDeadLetters(path string) (*DeadLetters, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening dead-letter file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("error closing dead-letter file: %w", err)
+	}
+
+	return &DeadLetters{
+		path: path,
+	}, nil
+}
+
+// Append appends the given dead letter to the file.
+func (d *DeadLetters) Append(letter DeadLetter) (err error) {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("error encoding dead letter: %w", err)
+	}
+	data = append(data, '\n')
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	unlock, err := lockFile(d.path)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, unlock()) }()
+
+	file, err := os.OpenFile(d.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening dead-letter file: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing dead letter: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing dead-letter file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the dead-letter file. The file is only open while appending, so there's nothing left to close.
+func (d *DeadLetters) Close() error {
+	return nil
+}
+
+// ReadDeadLetters reads every dead letter from the file at the given path. A missing file holds no dead letters.
+func ReadDeadLetters(path string) (letters []DeadLetter, err error) {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errors.Join(err, unlock()) }()
+
+	return readDeadLetters(path)
+}
+
+// readDeadLetters reads every dead letter from the file at the given path without locking it.
+func readDeadLetters(path string) ([]DeadLetter, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	var letters []DeadLetter
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var letter DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			return nil, fmt.Errorf("error decoding dead letter on line %d: %w", line, err)
+		}
+		letters = append(letters, letter)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading dead-letter file: %w", err)
+	}
+
+	return letters, nil
+}
+
+// ReplaceDeadLetters replaces the first read dead letters of the file at the given path with the given
+// dead letters. Dead letters appended since the first read were read are kept after them.
+func ReplaceDeadLetters(path string, read int, letters []DeadLetter) (err error) {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, unlock()) }()
+
+	current, err := readDeadLetters(path)
+	if err != nil {
+		return err
+	}
+	if read > len(current) {
+		return fmt.Errorf("dead-letter file has %d dead letters, fewer than the %d read from it", len(current), read)
+	}
+	letters = append(append([]DeadLetter(nil), letters...), current[read:]...)
+
+	var buf []byte
+	for _, letter := range letters {
+		data, err := json.Marshal(letter)
+		if err != nil {
+			return fmt.Errorf("error encoding dead letter: %w", err)
+		}
+		buf = append(buf, data...)
+		buf = append(buf, '\n')
+	}
+	return writeFile(path, buf)
+}
+
+// writeFile replaces the file at the given path with the given data. The data is written to a temporary
+// file that's renamed over the file so that a crash mid-write never loses its previous contents.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error replacing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package delivery
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+
+	letters, err := ReadDeadLetters(path)
+	require.NoError(t, err)
+	require.Empty(t, letters)
+
+	deadLetters, err := OpenDeadLetters(path)
+	require.NoError(t, err)
+
+	now := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+	first := DeadLetter{
+		Time:     now,
+		Notifier: "slack",
+		Queued:   now.Add(-time.Hour),
+		Attempts: 10,
+		Error:    "unavailable",
+		Message:  notifier.Message{Page: "GitHub", Changes: []notifier.Change{{Kind: notifier.KindStatus, Type: notifier.Updated}}},
+	}
+	second := DeadLetter{Time: now, Notifier: "stdout", Queued: now, Attempts: 1, Message: notifier.Message{Page: "GitHub"}}
+	require.NoError(t, deadLetters.Append(first))
+	require.NoError(t, deadLetters.Append(second))
+
+	letters, err = ReadDeadLetters(path)
+	require.NoError(t, err)
+	require.Equal(t, []DeadLetter{first, second}, letters)
+
+	// Dead letters appended after the file was read are kept when the ones read are replaced, and the open
+	// dead letters keep appending to the replaced file.
+	third := DeadLetter{Time: now, Notifier: "file", Queued: now, Attempts: 1, Message: notifier.Message{Page: "GitHub"}}
+	require.NoError(t, deadLetters.Append(third))
+	require.NoError(t, ReplaceDeadLetters(path, 2, []DeadLetter{second}))
+	fourth := DeadLetter{Time: now, Notifier: "slack", Queued: now, Attempts: 2, Message: notifier.Message{Page: "GitHub"}}
+	require.NoError(t, deadLetters.Append(fourth))
+
+	letters, err = ReadDeadLetters(path)
+	require.NoError(t, err)
+	require.Equal(t, []DeadLetter{second, third, fourth}, letters)
+}
@@ -0,0 +1,9 @@
+// Package delivery contains a queue that delivers notifications reliably.
+//
+// A queue wraps a notifier and delivers the messages given to it in order from a
+// background worker. Failed deliveries are retried with exponential backoff until they
+// succeed or the message becomes too old, at which point it is written to a dead-letter
+// file of JSON lines instead of being lost. Messages in the dead-letter file can later be
+// read back and redelivered. Messages waiting to be delivered can be kept in a spool file
+// so that they survive restarts and crashes.
+package delivery
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package delivery
+
+// lockFile doesn't lock anything on platforms without advisory file locks. The monitor and redeliver
+// shouldn't use the same dead-letter file at the same time on them.
+func lockFile(string) (func() error, error) {
+	return func() error { return nil }, nil
+}
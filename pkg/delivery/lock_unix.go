@@ -0,0 +1,24 @@
+//go:build unix
+
+package delivery
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on the lock file next to the file at the given path, waiting
+// for any other process holding it. The returned function releases the lock.
+func lockFile(path string) (func() error, error) {
+	file, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file: %w", err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error locking %s: %w", path, err)
+	}
+	// Closing the file releases the lock.
+	return file.Close, nil
+}
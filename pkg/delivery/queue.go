@@ -0,0 +1,298 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultInitialBackoff is the default delay before the first retry.
+	DefaultInitialBackoff = 5 * time.Second
+
+	// DefaultMaxBackoff is the default longest delay between retries.
+	DefaultMaxBackoff = 5 * time.Minute
+
+	// DefaultMaxAge is the default time after which undelivered messages are given up on.
+	DefaultMaxAge = time.Hour
+)
+
+// Config configures the retries of a queue.
+type Config struct {
+	// InitialBackoff is the delay before the first retry. The delay doubles with every retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the longest delay between retries.
+	MaxBackoff time.Duration
+
+	// MaxAge is how long after being queued a message is retried for before it's given up on. Zero gives up
+	// on messages after the first failed attempt.
+	MaxAge time.Duration
+
+	// Timeout is how long each delivery attempt is given before it counts as failed. Zero or less doesn't
+	// time out.
+	Timeout time.Duration
+
+	// DeadLetters is where messages that are given up on are written to. If this is nil, they're only logged.
+	DeadLetters *DeadLetters
+
+	// Spool is a file the messages waiting to be delivered are written to before they're accepted. Messages
+	// left in it when the queue stops, or if the process crashes, are delivered once a queue is created with
+	// it again. If this is empty, waiting messages are only kept in memory, are written to the dead letters
+	// when the queue is cleaned up and are lost on a crash.
+	Spool string
+}
+
+// queuedMessage is a message waiting to be delivered.
+type queuedMessage struct {
+	msg      notifier.Message
+	queued   time.Time
+	attempts int
+}
+
+// Queue is a notifier that delivers messages to another notifier in order from a background worker,
+// retrying failed deliveries.
+type Queue struct {
+	log    *zap.Logger
+	clock  clockwork.Clock
+	next   notifier.Notifier
+	config Config
+
+	// deliverer keeps the worker from calling the notifier again while it's stuck in a call that timed out.
+	deliverer notifier.Deliverer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{}
+	done   chan struct{}
+
+	mu      sync.Mutex
+	pending []queuedMessage
+	closed  bool
+}
+
+var _ notifier.Queuing = &Queue{}
+
+// NewQueue returns a queue delivering to the given notifier and starts its worker. Messages left in the
+// spool are delivered first. The worker is stopped by Cleanup.
+func NewQueue(log *zap.Logger, clock clockwork.Clock, next notifier.Notifier, config Config) (*Queue, error) {
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+
+	var pending []queuedMessage
+	if config.Spool != "" {
+		var err error
+		pending, err = readSpool(config.Spool)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		log:     log.With(zap.String("notifier", next.Name())),
+		clock:   clock,
+		next:    next,
+		config:  config,
+		ctx:     ctx,
+		cancel:  cancel,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		pending: pending,
+	}
+	if len(pending) > 0 {
+		q.log.With(zap.Int("messages", len(pending))).Info("Delivering messages left in the spool.")
+	}
+	go q.run()
+	return q, nil
+}
+
+// Name is the name of the underlying notifier.
+func (q *Queue) Name() string {
+	return q.next.Name()
+}
+
+// Queues returns true, as messages are delivered by the worker after Notify returns.
+func (q *Queue) Queues() bool {
+	return true
+}
+
+// Notify queues the message for delivery. This doesn't wait for the message to be delivered, but does wait
+// for it to be written to the spool if there is one.
+func (q *Queue) Notify(_ context.Context, msg notifier.Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return fmt.Errorf("delivery queue for %s is closed", q.next.Name())
+	}
+	q.pending = append(q.pending, queuedMessage{msg: msg, queued: q.clock.Now()})
+	if err := q.spool(); err != nil {
+		q.pending = q.pending[:len(q.pending)-1]
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Len returns the number of messages waiting to be delivered.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Cleanup stops the worker, writes the messages that haven't been delivered yet to the dead-letter file
+// and cleans up the underlying notifier. With a spool, the messages that haven't been delivered are left in
+// it instead.
+func (q *Queue) Cleanup() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.mu.Unlock()
+
+	q.cancel()
+	<-q.done
+
+	var errs []error
+	if q.config.Spool != "" {
+		if len(q.pending) > 0 {
+			q.log.With(zap.Int("messages", len(q.pending))).Info("Leaving undelivered messages in the spool.")
+		}
+		errs = append(errs, q.spool())
+	} else {
+		for _, queued := range q.pending {
+			errs = append(errs, q.deadLetter(queued, errors.New("not delivered before shutdown")))
+		}
+	}
+	q.pending = nil
+
+	errs = append(errs, q.next.Cleanup())
+	return errors.Join(errs...)
+}
+
+// run delivers the queued messages one at a time until the queue is cleaned up. A message is only
+// delivered once every message queued before it has been delivered or given up on.
+func (q *Queue) run() {
+	defer close(q.done)
+
+	backoff := q.config.InitialBackoff
+	for {
+		queued, ok := q.head()
+		if !ok {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.ctx.Done():
+				return
+			}
+		}
+
+		err := q.deliverer.Deliver(q.ctx, q.next, queued.msg, q.config.Timeout)
+		queued.attempts++
+		if err == nil {
+			q.pop()
+			backoff = q.config.InitialBackoff
+			continue
+		}
+		if q.ctx.Err() != nil {
+			// The queue is being cleaned up, which takes care of the messages that are left.
+			q.setAttempts(queued.attempts)
+			return
+		}
+
+		if q.clock.Since(queued.queued) >= q.config.MaxAge {
+			q.log.With(zap.Error(err), zap.Int("attempts", queued.attempts)).Error("giving up on delivering message")
+			if err := q.deadLetter(queued, err); err != nil {
+				q.log.With(zap.Error(err)).Error("error writing dead letter")
+			}
+			q.pop()
+			backoff = q.config.InitialBackoff
+			continue
+		}
+
+		q.setAttempts(queued.attempts)
+		q.log.With(zap.Error(err), zap.Duration("backoff", backoff)).Warn("error delivering message, retrying")
+		select {
+		case <-q.clock.After(backoff):
+		case <-q.ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > q.config.MaxBackoff {
+			backoff = q.config.MaxBackoff
+		}
+	}
+}
+
+// head returns the message at the head of the queue.
+func (q *Queue) head() (queuedMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return queuedMessage{}, false
+	}
+	return q.pending[0], true
+}
+
+// pop removes the message at the head of the queue.
+func (q *Queue) pop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = q.pending[1:]
+	if err := q.spool(); err != nil {
+		q.log.With(zap.Error(err)).Error("error removing message from the spool")
+	}
+}
+
+// setAttempts records the number of delivery attempts made for the message at the head of the queue.
+func (q *Queue) setAttempts(attempts int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending[0].attempts = attempts
+	if err := q.spool(); err != nil {
+		q.log.With(zap.Error(err)).Error("error updating message in the spool")
+	}
+}
+
+// spool writes the messages waiting to be delivered to the spool, if there is one. The caller must hold mu.
+func (q *Queue) spool() error {
+	if q.config.Spool == "" {
+		return nil
+	}
+	return writeSpool(q.config.Spool, q.pending)
+}
+
+// deadLetter writes the message to the dead-letter file, or logs it if there isn't one.
+func (q *Queue) deadLetter(queued queuedMessage, err error) error {
+	if q.config.DeadLetters == nil {
+		q.log.With(zap.Any("message", queued.msg)).Error("dropping undeliverable message")
+		return nil
+	}
+
+	return q.config.DeadLetters.Append(DeadLetter{
+		Time:     q.clock.Now().UTC(),
+		Notifier: q.next.Name(),
+		Queued:   queued.queued.UTC(),
+		Attempts: queued.attempts,
+		Error:    err.Error(),
+		Message:  queued.msg,
+	})
+}
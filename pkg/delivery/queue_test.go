@@ -0,0 +1,170 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// flakyNotifier fails the given number of deliveries before succeeding, and sends every delivered message
+// to its channel.
+type flakyNotifier struct {
+	failures int
+	attempts chan struct{}
+	ch       chan notifier.Message
+}
+
+func (f *flakyNotifier) Name() string {
+	return "flaky"
+}
+
+func (f *flakyNotifier) Notify(_ context.Context, msg notifier.Message) error {
+	defer func() { f.attempts <- struct{}{} }()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unavailable")
+	}
+	f.ch <- msg
+	return nil
+}
+
+func (f *flakyNotifier) Cleanup() error {
+	return nil
+}
+
+func newFlakyNotifier(failures int) *flakyNotifier {
+	return &flakyNotifier{
+		failures: failures,
+		attempts: make(chan struct{}, 10),
+		ch:       make(chan notifier.Message, 10),
+	}
+}
+
+func waitForAttempt(t *testing.T, f *flakyNotifier) {
+	select {
+	case <-f.attempts:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no delivery attempt")
+	}
+}
+
+func TestQueueRetriesInOrder(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	next := newFlakyNotifier(2)
+	q, err := NewQueue(zap.NewNop(), clock, next, Config{InitialBackoff: time.Second, MaxBackoff: time.Second, MaxAge: time.Hour})
+	require.NoError(t, err)
+	defer q.Cleanup()
+
+	require.NoError(t, q.Notify(context.Background(), notifier.Message{Page: "first"}))
+	require.NoError(t, q.Notify(context.Background(), notifier.Message{Page: "second"}))
+
+	for i := 0; i < 2; i++ {
+		waitForAttempt(t, next)
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+
+	require.Equal(t, "first", (<-next.ch).Page)
+	require.Equal(t, "second", (<-next.ch).Page)
+}
+
+func TestQueueDeadLetters(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	deadLetters, err := OpenDeadLetters(path)
+	require.NoError(t, err)
+	defer deadLetters.Close()
+
+	next := newFlakyNotifier(100)
+	q, err := NewQueue(zap.NewNop(), clock, next, Config{
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Minute,
+		MaxAge:         2 * time.Minute,
+		DeadLetters:    deadLetters,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, q.Notify(context.Background(), notifier.Message{Page: "expired"}))
+	for i := 0; i < 2; i++ {
+		waitForAttempt(t, next)
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+	}
+	// The third attempt is made two minutes after the message was queued, after which it's given up on.
+	waitForAttempt(t, next)
+
+	require.NoError(t, q.Notify(context.Background(), notifier.Message{Page: "pending"}))
+	waitForAttempt(t, next)
+	clock.BlockUntil(1)
+	require.NoError(t, q.Cleanup())
+	require.Zero(t, q.Len())
+
+	letters, err := ReadDeadLetters(path)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+
+	require.Equal(t, "flaky", letters[0].Notifier)
+	require.Equal(t, "expired", letters[0].Message.Page)
+	require.Equal(t, 3, letters[0].Attempts)
+	require.Equal(t, "unavailable", letters[0].Error)
+
+	require.Equal(t, "pending", letters[1].Message.Page)
+	require.Equal(t, 1, letters[1].Attempts)
+	require.Equal(t, "not delivered before shutdown", letters[1].Error)
+
+	require.Error(t, q.Notify(context.Background(), notifier.Message{Page: "closed"}))
+}
+
+func TestQueueSpool(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	dir := t.TempDir()
+	deadLetters, err := OpenDeadLetters(filepath.Join(dir, "dead-letters.jsonl"))
+	require.NoError(t, err)
+	config := Config{
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Minute,
+		MaxAge:         time.Hour,
+		DeadLetters:    deadLetters,
+		Spool:          filepath.Join(dir, "flaky.json"),
+	}
+
+	// Messages that haven't been delivered when the queue stops are left in the spool rather than given up on.
+	failing := newFlakyNotifier(100)
+	q, err := NewQueue(zap.NewNop(), clock, failing, config)
+	require.NoError(t, err)
+	require.NoError(t, q.Notify(context.Background(), notifier.Message{Page: "first"}))
+	require.NoError(t, q.Notify(context.Background(), notifier.Message{Page: "second"}))
+	waitForAttempt(t, failing)
+	clock.BlockUntil(1)
+	require.NoError(t, q.Cleanup())
+
+	letters, err := ReadDeadLetters(filepath.Join(dir, "dead-letters.jsonl"))
+	require.NoError(t, err)
+	require.Empty(t, letters)
+	pending, err := readSpool(config.Spool)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, 1, pending[0].attempts)
+
+	// A new queue with the same spool delivers them in order, and removes them from the spool.
+	next := newFlakyNotifier(0)
+	q, err = NewQueue(zap.NewNop(), clock, next, config)
+	require.NoError(t, err)
+	defer q.Cleanup()
+	require.Equal(t, "first", (<-next.ch).Page)
+	require.Equal(t, "second", (<-next.ch).Page)
+	waitForAttempt(t, next)
+	waitForAttempt(t, next)
+
+	require.Eventually(t, func() bool {
+		pending, err := readSpool(config.Spool)
+		return err == nil && len(pending) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,61 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// spooledMessage is a message waiting to be delivered as it's stored in a spool file.
+type spooledMessage struct {
+	// Queued is when the message was first queued for delivery.
+	Queued time.Time `json:"queued"`
+
+	// Attempts is the number of delivery attempts that have been made.
+	Attempts int `json:"attempts"`
+
+	// Message is the message to deliver.
+	Message notifier.Message `json:"message"`
+}
+
+// readSpool reads the messages waiting to be delivered from the spool file at the given path. A missing file
+// holds no messages.
+func readSpool(path string) ([]queuedMessage, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading spool file: %w", err)
+	}
+
+	var spooled []spooledMessage
+	if err := json.Unmarshal(data, &spooled); err != nil {
+		return nil, fmt.Errorf("error decoding spool file: %w", err)
+	}
+
+	pending := make([]queuedMessage, 0, len(spooled))
+	for _, message := range spooled {
+		pending = append(pending, queuedMessage{msg: message.Message, queued: message.Queued, attempts: message.Attempts})
+	}
+	return pending, nil
+}
+
+// writeSpool replaces the contents of the spool file at the given path with the given messages.
+func writeSpool(path string, pending []queuedMessage) error {
+	spooled := make([]spooledMessage, 0, len(pending))
+	for _, queued := range pending {
+		spooled = append(spooled, spooledMessage{Queued: queued.queued.UTC(), Attempts: queued.attempts, Message: queued.msg})
+	}
+
+	data, err := json.Marshal(spooled)
+	if err != nil {
+		return fmt.Errorf("error encoding spool file: %w", err)
+	}
+	return writeFile(path, data)
+}
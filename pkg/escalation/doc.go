@@ -0,0 +1,16 @@
+// Package escalation decides when to re-notify about incidents that remain unresolved.
+//
+// An escalation policy fires once for every incident of at least a given severity that is
+// still unresolved a given time after the monitor first saw it. A policy re-notifies the
+// notifiers the incident would normally be sent to, or the notifiers it names instead, and
+// is typically declared in the config file:
+//
+//	escalations:
+//	  - name: still-down
+//	    min_severity: major
+//	    after: 30m
+//	  - name: page
+//	    min_severity: major
+//	    after: 2h
+//	    notifiers: [pagerduty]
+package escalation
@@ -0,0 +1,145 @@
+package escalation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+)
+
+// Policy re-notifies about incidents that are still unresolved after a while.
+type Policy struct {
+	// Name identifies the policy. Policies without a name are identified by their position, so naming
+	// policies keeps escalations that have already fired from firing again when the policies are reordered.
+	Name string `mapstructure:"name" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// MinSeverity is the minimum severity of the incidents the policy applies to. Policies without a minimum
+	// severity apply to every incident.
+	MinSeverity string `mapstructure:"min_severity" json:"min_severity,omitempty" yaml:"min_severity,omitempty"`
+
+	// After is how long an incident must have been unresolved for before the policy fires.
+	After time.Duration `mapstructure:"after" json:"after" yaml:"after"`
+
+	// Notifiers are the names of the notifiers to send the escalation to. Policies without notifiers send it
+	// to the notifiers the incident is routed to.
+	Notifiers []string `mapstructure:"notifiers" json:"notifiers,omitempty" yaml:"notifiers,omitempty"`
+}
+
+// Step is an escalation that is due.
+type Step struct {
+	// ID identifies the policy that fired.
+	ID string
+
+	// After is how long the incident has been unresolved for according to the policy.
+	After time.Duration
+
+	// Notifiers are the notifiers to send the escalation to, if the policy names any.
+	Notifiers []string
+}
+
+// compiledPolicy is a policy with its severity parsed.
+type compiledPolicy struct {
+	step        Step
+	minSeverity ghstatus.Severity
+}
+
+// Policies are a set of escalation policies.
+type Policies struct {
+	policies []compiledPolicy
+}
+
+// New creates new escalation policies.
+func New(policies []Policy) (*Policies, error) {
+	p := &Policies{}
+	ids := map[string]struct{}{}
+	for i, policy := range policies {
+		id := policy.Name
+		if id == "" {
+			id = fmt.Sprintf("#%d", i+1)
+		}
+		if _, ok := ids[id]; ok {
+			return nil, fmt.Errorf("duplicate escalation %s", id)
+		}
+		ids[id] = struct{}{}
+
+		compiled, err := compile(id, policy)
+		if err != nil {
+			return nil, fmt.Errorf("error in escalation %s: %w", id, err)
+		}
+		p.policies = append(p.policies, compiled)
+	}
+
+	sort.SliceStable(p.policies, func(i, j int) bool {
+		return p.policies[i].step.After < p.policies[j].step.After
+	})
+	return p, nil
+}
+
+// Notifiers returns the names of every notifier the policies name.
+func (p *Policies) Notifiers() []string {
+	names := map[string]struct{}{}
+	for _, policy := range p.policies {
+		for _, name := range policy.step.Notifiers {
+			names[name] = struct{}{}
+		}
+	}
+
+	notifiers := make([]string, 0, len(names))
+	for name := range names {
+		notifiers = append(notifiers, name)
+	}
+	sort.Strings(notifiers)
+	return notifiers
+}
+
+// compile parses the policy.
+func compile(id string, policy Policy) (compiledPolicy, error) {
+	if policy.After <= 0 {
+		return compiledPolicy{}, errors.New("after must be positive")
+	}
+
+	compiled := compiledPolicy{
+		step: Step{
+			ID:        id,
+			After:     policy.After,
+			Notifiers: policy.Notifiers,
+		},
+		minSeverity: ghstatus.SeverityUnknown,
+	}
+	if policy.MinSeverity != "" {
+		var err error
+		if compiled.minSeverity, err = ghstatus.ParseSeverity(policy.MinSeverity); err != nil {
+			return compiledPolicy{}, err
+		}
+	}
+	return compiled, nil
+}
+
+// Due returns the escalations that are due for an incident of the given severity that has been unresolved
+// for the given duration, leaving out those that have already fired. Escalations are returned in the order
+// of their delays. Incidents of unknown severity only match policies without a minimum severity.
+func (p *Policies) Due(severity ghstatus.Severity, unresolvedFor time.Duration, fired []string) []Step {
+	var due []Step
+	for _, policy := range p.policies {
+		if unresolvedFor < policy.step.After || contains(fired, policy.step.ID) {
+			continue
+		}
+		if !severity.AtLeast(policy.minSeverity) {
+			continue
+		}
+		due = append(due, policy.step)
+	}
+	return due
+}
+
+// contains returns true if the value is in the list.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package escalation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDue(t *testing.T) {
+	policies, err := New([]Policy{
+		{Name: "page", MinSeverity: "major", After: 2 * time.Hour, Notifiers: []string{"pager"}},
+		{Name: "still-down", MinSeverity: "major", After: 30 * time.Minute},
+		{After: time.Hour},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"pager"}, policies.Notifiers())
+
+	ids := func(steps []Step) []string {
+		var ids []string
+		for _, step := range steps {
+			ids = append(ids, step.ID)
+		}
+		return ids
+	}
+
+	require.Empty(t, policies.Due(ghstatus.SeverityCritical, 29*time.Minute, nil))
+	require.Equal(t, []string{"still-down"}, ids(policies.Due(ghstatus.SeverityMajor, 30*time.Minute, nil)))
+	require.Equal(t, []string{"still-down", "#3", "page"}, ids(policies.Due(ghstatus.SeverityCritical, 3*time.Hour, nil)))
+	require.Equal(t, []string{"page"}, ids(policies.Due(ghstatus.SeverityCritical, 3*time.Hour, []string{"still-down", "#3"})))
+	require.Equal(t, []string{"#3"}, ids(policies.Due(ghstatus.SeverityMinor, 3*time.Hour, nil)))
+	require.Equal(t, []string{"#3"}, ids(policies.Due(ghstatus.SeverityUnknown, 3*time.Hour, nil)))
+
+	steps := policies.Due(ghstatus.SeverityCritical, 3*time.Hour, []string{"still-down", "#3"})
+	require.Equal(t, []Step{{ID: "page", After: 2 * time.Hour, Notifiers: []string{"pager"}}}, steps)
+}
+
+func TestNewValidatesPolicies(t *testing.T) {
+	_, err := New([]Policy{{Name: "never"}})
+	require.ErrorContains(t, err, "escalation never")
+
+	_, err = New([]Policy{{After: time.Hour, MinSeverity: "bogus"}})
+	require.ErrorContains(t, err, "escalation #1")
+
+	_, err = New([]Policy{{Name: "twice", After: time.Hour}, {Name: "twice", After: 2 * time.Hour}})
+	require.ErrorContains(t, err, "duplicate escalation twice")
+}
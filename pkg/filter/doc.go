@@ -0,0 +1,7 @@
+// Package filter decides which detected changes are relevant.
+//
+// Changes can be narrowed down to the components they concern, using component names,
+// globs or regular expressions, and to a minimum severity. Components are matched by
+// their own name or the name of their group, while incidents and scheduled maintenances
+// are matched by the components they affect.
+package filter
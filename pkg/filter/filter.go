@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// Filter decides whether changes are relevant based on the components they concern and their severity.
+type Filter struct {
+	include     Patterns
+	exclude     Patterns
+	minSeverity ghstatus.Severity
+}
+
+// New creates a new filter. Only changes concerning components that match one of the include patterns,
+// if there are any, and none of the exclude patterns are kept. Changes less severe than the minimum
+// severity are dropped.
+func New(include []string, exclude []string, minSeverity ghstatus.Severity) (*Filter, error) {
+	includePatterns, err := ParsePatterns(include)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing include patterns: %w", err)
+	}
+	excludePatterns, err := ParsePatterns(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing exclude patterns: %w", err)
+	}
+
+	return &Filter{
+		include:     includePatterns,
+		exclude:     excludePatterns,
+		minSeverity: minSeverity,
+	}, nil
+}
+
+// Matches returns true if the change passes the filter.
+//
+// Changes of unknown severity always pass the severity check so that values Statuspage adds in the
+// future aren't silently dropped. The overall status isn't about any particular component, and neither
+// are incidents and scheduled maintenances that don't list the components they affect, so these are
+// only subject to the severity check.
+func (f *Filter) Matches(change notifier.Change) bool {
+	if change.Severity != ghstatus.SeverityUnknown && !change.Severity.AtLeast(f.minSeverity) {
+		return false
+	}
+
+	switch change.Kind {
+	case notifier.KindComponent:
+		return f.matchesComponent(change.Name, change.Group)
+	case notifier.KindIncident, notifier.KindScheduledMaintenance:
+		if len(change.Components) == 0 {
+			return true
+		}
+		for _, component := range change.Components {
+			if f.matchesComponent(component) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// matchesComponent returns true if a component with the given names passes the component filters.
+func (f *Filter) matchesComponent(names ...string) bool {
+	if f.exclude.MatchAny(names...) {
+		return false
+	}
+	return len(f.include) == 0 || f.include.MatchAny(names...)
+}
@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatterns(t *testing.T) {
+	patterns, err := ParsePatterns([]string{"Actions", "Git *", "re:^Pack(ages)?$"})
+	require.NoError(t, err)
+
+	require.True(t, patterns.MatchAny("Actions"))
+	require.True(t, patterns.MatchAny("Git Operations"))
+	require.True(t, patterns.MatchAny("Packages"))
+	require.False(t, patterns.MatchAny("GitHub Packages"))
+	require.False(t, patterns.MatchAny("Codespaces"))
+	require.False(t, patterns.MatchAny(""))
+
+	_, err = ParsePattern("re:(")
+	require.Error(t, err)
+	_, err = ParsePattern("[")
+	require.Error(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	f, err := New([]string{"Actions", "Git *", "CI/CD"}, []string{"Packages"}, ghstatus.SeverityMajor)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		change  notifier.Change
+		matches bool
+	}{
+		{
+			name:    "included component",
+			change:  notifier.Change{Kind: notifier.KindComponent, Name: "Actions", Severity: ghstatus.SeverityMajor},
+			matches: true,
+		},
+		{
+			name:    "included component that isn't severe enough",
+			change:  notifier.Change{Kind: notifier.KindComponent, Name: "Actions", Severity: ghstatus.SeverityMinor},
+			matches: false,
+		},
+		{
+			name:    "component matched by glob",
+			change:  notifier.Change{Kind: notifier.KindComponent, Name: "Git Operations", Severity: ghstatus.SeverityCritical},
+			matches: true,
+		},
+		{
+			name:    "component that isn't included",
+			change:  notifier.Change{Kind: notifier.KindComponent, Name: "Codespaces", Severity: ghstatus.SeverityCritical},
+			matches: false,
+		},
+		{
+			name:    "component included by its group",
+			change:  notifier.Change{Kind: notifier.KindComponent, Name: "Runners", Group: "CI/CD", Severity: ghstatus.SeverityMajor},
+			matches: true,
+		},
+		{
+			name:    "excluded component in an included group",
+			change:  notifier.Change{Kind: notifier.KindComponent, Name: "Packages", Group: "CI/CD", Severity: ghstatus.SeverityMajor},
+			matches: false,
+		},
+		{
+			name: "incident affecting an included component",
+			change: notifier.Change{Kind: notifier.KindIncident, Components: []string{"Codespaces", "Actions"},
+				Severity: ghstatus.SeverityMajor},
+			matches: true,
+		},
+		{
+			name: "incident only affecting other components",
+			change: notifier.Change{Kind: notifier.KindIncident, Components: []string{"Codespaces", "Packages"},
+				Severity: ghstatus.SeverityMajor},
+			matches: false,
+		},
+		{
+			name:    "incident without components",
+			change:  notifier.Change{Kind: notifier.KindIncident, Severity: ghstatus.SeverityCritical},
+			matches: true,
+		},
+		{
+			name:    "status that isn't severe enough",
+			change:  notifier.Change{Kind: notifier.KindStatus, Severity: ghstatus.SeverityMinor},
+			matches: false,
+		},
+		{
+			name:    "unknown severity",
+			change:  notifier.Change{Kind: notifier.KindComponent, Name: "Actions", Severity: ghstatus.SeverityUnknown},
+			matches: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.matches, f.Matches(test.change))
+		})
+	}
+}
+
+func TestFilterMessage(t *testing.T) {
+	f, err := New([]string{"Actions"}, nil, ghstatus.SeverityUnknown)
+	require.NoError(t, err)
+
+	actions := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.MajorOutage}
+	pages := ghstatus.Component{ID: "pages", Name: "Pages", Status: ghstatus.MajorOutage}
+	msg := notifier.Message{
+		Page:              ghstatus.GithubPageName,
+		ChangedStatus:     &ghstatus.Status{Indicator: ghstatus.Major},
+		ChangedComponents: []ghstatus.Component{actions, pages},
+		Changes: []notifier.Change{
+			{Kind: notifier.KindStatus, Severity: ghstatus.SeverityMajor},
+			{Kind: notifier.KindComponent, ID: "actions", Name: "Actions", Severity: ghstatus.SeverityCritical},
+			{Kind: notifier.KindComponent, ID: "pages", Name: "Pages", Severity: ghstatus.SeverityCritical},
+		},
+	}
+
+	require.Equal(t, notifier.Message{
+		Page:              ghstatus.GithubPageName,
+		ChangedStatus:     msg.ChangedStatus,
+		ChangedComponents: []ghstatus.Component{actions},
+		Changes:           []notifier.Change{msg.Changes[0], msg.Changes[1]},
+	}, msg.Filter(f.Matches))
+
+	require.True(t, msg.Filter(func(notifier.Change) bool { return false }).Empty())
+}
@@ -0,0 +1,78 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// regexPrefix designates a pattern as a regular expression.
+	regexPrefix = "re:"
+)
+
+// Pattern matches names. A pattern is either a glob, which also covers exact names, or a regular
+// expression when prefixed with "re:".
+type Pattern struct {
+	raw   string
+	regex *regexp.Regexp
+}
+
+// ParsePattern parses the given pattern.
+func ParsePattern(pattern string) (Pattern, error) {
+	if expr, ok := strings.CutPrefix(pattern, regexPrefix); ok {
+		regex, err := regexp.Compile(expr)
+		if err != nil {
+			return Pattern{}, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+		}
+		return Pattern{raw: pattern, regex: regex}, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return Pattern{}, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return Pattern{raw: pattern}, nil
+}
+
+// Match returns true if the name matches the pattern.
+func (p Pattern) Match(name string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(name)
+	}
+	matched, _ := path.Match(p.raw, name)
+	return matched
+}
+
+// String returns the pattern as it was given.
+func (p Pattern) String() string {
+	return p.raw
+}
+
+// Patterns is a list of patterns.
+type Patterns []Pattern
+
+// ParsePatterns parses each of the given patterns.
+func ParsePatterns(patterns []string) (Patterns, error) {
+	parsed := make(Patterns, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, err := ParsePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+// MatchAny returns true if any of the names match any of the patterns.
+func (ps Patterns) MatchAny(names ...string) bool {
+	for _, p := range ps {
+		for _, name := range names {
+			if name != "" && p.Match(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package ghstatus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// cachedResponse is a response body along with the validators used to revalidate it.
+type cachedResponse struct {
+	// ETag is the entity tag of the response, if it had one.
+	ETag string `json:"etag,omitempty"`
+
+	// LastModified is the last modification time of the response, if it had one.
+	LastModified string `json:"last_modified,omitempty"`
+
+	// Fetched is when the response was last fetched or revalidated.
+	Fetched time.Time `json:"fetched"`
+
+	// Body is the body of the response.
+	Body json.RawMessage `json:"body"`
+}
+
+// responseCache keeps the last response from each endpoint in memory so that it can be requested
+// conditionally, and on disk if there's a directory so that it can be shared across processes.
+type responseCache struct {
+	clock clockwork.Clock
+	dir   string
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	responses map[string]cachedResponse
+}
+
+// newResponseCache creates a response cache. Responses are only kept in memory if the directory is empty.
+func newResponseCache(clock clockwork.Clock, dir string, ttl time.Duration) *responseCache {
+	return &responseCache{
+		clock:     clock,
+		dir:       dir,
+		ttl:       ttl,
+		responses: map[string]cachedResponse{},
+	}
+}
+
+// get returns the cached response from the endpoint, if there is one.
+func (c *responseCache) get(endpoint string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if resp, ok := c.responses[endpoint]; ok {
+		return resp, true
+	}
+	if c.dir == "" {
+		return cachedResponse{}, false
+	}
+
+	// A cache file that can't be read is treated like a missing one, it'll be replaced after the next request.
+	data, err := os.ReadFile(c.path(endpoint))
+	if err != nil {
+		return cachedResponse{}, false
+	}
+	var resp cachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return cachedResponse{}, false
+	}
+	c.responses[endpoint] = resp
+	return resp, true
+}
+
+// fresh returns true if the cached response can be used without revalidating it.
+func (c *responseCache) fresh(resp cachedResponse) bool {
+	return c.ttl > 0 && c.clock.Since(resp.Fetched) < c.ttl
+}
+
+// put caches the response from the endpoint.
+func (c *responseCache) put(endpoint string, resp cachedResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.responses[endpoint] = resp
+	if c.dir == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("error encoding cached response: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("error creating cache directory: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the cache file so that other processes never read a
+	// partially written response.
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(endpoint)); err != nil {
+		return fmt.Errorf("error replacing cache file: %w", err)
+	}
+	return nil
+}
+
+// path returns the path of the cache file for the endpoint.
+func (c *responseCache) path(endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,153 @@
+package ghstatus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errAbandoned is returned to callers waiting on a request that never finished.
+var errAbandoned = errors.New("request in flight was abandoned")
+
+// call is a request in flight that identical requests wait for instead of making their own.
+type call struct {
+	done chan struct{}
+	val  any
+	err  error
+
+	// callers is the number of callers still waiting for the request, which is canceled once there are none.
+	callers int
+	ctx     *callContext
+}
+
+// coalescer coalesces identical requests made concurrently so that only one of them is in flight.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// do calls fn unless a call with the same key is already in flight, in which case it waits for that
+// call and returns its result. The result is shared between the callers. fn runs with a context of its
+// own that is only canceled once every caller waiting for it has given up, so that one caller giving up
+// doesn't fail the others. The context carries the values of the caller that made the call and expires
+// at the earliest deadline of the callers that waited for it.
+func (c *coalescer) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	c.mu.Lock()
+	current, ok := c.calls[key]
+	if !ok {
+		callCtx := newCallContext(ctx)
+		current = &call{done: make(chan struct{}), err: errAbandoned, ctx: callCtx}
+		if c.calls == nil {
+			c.calls = map[string]*call{}
+		}
+		c.calls[key] = current
+
+		go func() {
+			defer func() {
+				c.mu.Lock()
+				if c.calls[key] == current {
+					delete(c.calls, key)
+				}
+				c.mu.Unlock()
+				callCtx.cancel(context.Canceled)
+				close(current.done)
+			}()
+			current.val, current.err = fn(callCtx)
+		}()
+	}
+	current.callers++
+	if deadline, ok := ctx.Deadline(); ok {
+		current.ctx.expireBy(deadline)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-current.done:
+		return current.val, current.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		current.callers--
+		if current.callers == 0 {
+			// Nobody is waiting for the request anymore, so later callers start a new one.
+			current.ctx.cancel(context.Canceled)
+			if c.calls[key] == current {
+				delete(c.calls, key)
+			}
+		}
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// callContext is the context a coalesced call runs with. It has the values of the context it's created from,
+// but isn't canceled with it. It's canceled explicitly or once its deadline, which can only be brought
+// forward, has passed.
+type callContext struct {
+	values context.Context
+	done   chan struct{}
+
+	mu       sync.Mutex
+	err      error
+	deadline time.Time
+	timer    *time.Timer
+}
+
+var _ context.Context = &callContext{}
+
+// newCallContext returns a call context with the values of the given context.
+func newCallContext(values context.Context) *callContext {
+	return &callContext{values: values, done: make(chan struct{})}
+}
+
+// Deadline returns the earliest deadline the context has been given.
+func (c *callContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, !c.deadline.IsZero()
+}
+
+// Done returns a channel that's closed once the context is canceled or its deadline has passed.
+func (c *callContext) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the context is done, or nil if it isn't.
+func (c *callContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Value returns the value for the key from the context the call context was created from.
+func (c *callContext) Value(key any) any {
+	return c.values.Value(key)
+}
+
+// expireBy brings the deadline of the context forward to the given deadline if it's earlier.
+func (c *callContext) expireBy(deadline time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || (!c.deadline.IsZero() && !deadline.Before(c.deadline)) {
+		return
+	}
+	c.deadline = deadline
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(time.Until(deadline), func() { c.cancel(context.DeadlineExceeded) })
+}
+
+// cancel ends the context with the given error if it hasn't ended already.
+func (c *callContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	close(c.done)
+}
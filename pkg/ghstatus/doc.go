@@ -5,8 +5,24 @@
 // Github Status API, which is documented (incompletely) here:
 // https://www.githubstatus.com/api
 //
+// The Github Status page is hosted by Statuspage, so the same client can be
+// used against any other Statuspage-hosted page (npm, Cloudflare, Docker Hub, ...)
+// by passing WithBaseURL and WithPageName to NewClient.
+//
 // This is a relatively simple API. Retries were added by using a
 // retryable HTTP client, but no other features pertaining to API rate limiting
-// or caching have been added. As of this writing, no API rate limits are documented
+// have been added. As of this writing, no API rate limits are documented
 // in the API, so it is currently assumed there either is no rate limit or it is very high.
+//
+// The client remembers the last response from each endpoint and requests it again with
+// ETag and Last-Modified validators, so unchanged responses aren't downloaded again.
+// WithCache additionally keeps the responses on disk with a TTL so that they can be
+// shared across processes. With WithStaleOnError, the last fetched response is returned, marked
+// as stale, if a response can't be fetched.
+//
+// Concurrent requests for the same endpoint are coalesced into one, and Snapshot fetches several
+// endpoints in parallel.
+//
+// Responses other than 2xx are returned as a *StatusError, or a *RateLimitError if the
+// API is rate limiting, and responses that can't be decoded as a *DecodeError.
 package ghstatus
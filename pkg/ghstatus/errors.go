@@ -0,0 +1,166 @@
+package ghstatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+const (
+	// maxSnippetLength is the longest part of a response body kept in errors.
+	maxSnippetLength = 256
+)
+
+// StatusError is returned when the status API responds with a status other than 2xx.
+type StatusError struct {
+	// Endpoint is the URL that was requested.
+	Endpoint string
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Body is the start of the response body.
+	Body string
+}
+
+// Error describes the response.
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("status API returned %d %s for %s", e.StatusCode, http.StatusText(e.StatusCode), e.Endpoint)
+	if e.Body != "" {
+		msg += fmt.Sprintf(": %q", e.Body)
+	}
+	return msg
+}
+
+// Temporary returns true if the request may succeed when retried later.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// RateLimitError is returned when the status API responds with 429 Too Many Requests.
+type RateLimitError struct {
+	StatusError
+
+	// RetryAfter is how long the status API asked to wait before retrying. This is zero if it didn't say.
+	RetryAfter time.Duration
+}
+
+// Error describes the response.
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited by the status API for %s, retry after %s", e.Endpoint, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited by the status API for %s", e.Endpoint)
+}
+
+// Unwrap returns the underlying status error.
+func (e *RateLimitError) Unwrap() error {
+	return &e.StatusError
+}
+
+// DecodeError is returned when the response from the status API can't be decoded.
+type DecodeError struct {
+	// Endpoint is the URL that was requested.
+	Endpoint string
+
+	// ContentType is the content type of the response.
+	ContentType string
+
+	// Body is the start of the response body.
+	Body string
+
+	// Err is the error decoding the response.
+	Err error
+}
+
+// Error describes the response.
+func (e *DecodeError) Error() string {
+	if e.ContentType == "" {
+		return fmt.Sprintf("error decoding response from %s: %v: %q", e.Endpoint, e.Err, e.Body)
+	}
+	return fmt.Sprintf("error decoding %s response from %s: %v: %q", e.ContentType, e.Endpoint, e.Err, e.Body)
+}
+
+// Unwrap returns the error decoding the response.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary returns true if the error may not happen when the request is retried later. This includes
+// responses from the status API such as server errors or rate limiting, and failures to reach it such as
+// DNS errors, refused connections and timeouts. Responses that can't be decoded are considered temporary
+// as well, since outages often put an HTML error page in front of the API.
+func Temporary(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		return true
+	}
+
+	// A *url.Error is a net.Error itself, so look at its cause to leave out invalid URLs.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// newResponseError returns the error for a response with a status other than 2xx.
+func newResponseError(clock clockwork.Clock, endpoint string, resp *http.Response, body []byte) error {
+	statusErr := StatusError{
+		Endpoint:   endpoint,
+		StatusCode: resp.StatusCode,
+		Body:       snippet(body),
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{StatusError: statusErr, RetryAfter: retryAfter(clock, resp.Header.Get("Retry-After"))}
+	}
+	return &statusErr
+}
+
+// retryAfter parses the value of a Retry-After header, which is either a number of seconds or a date. Dates
+// are relative to the given clock.
+func retryAfter(clock clockwork.Clock, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(clock.Now()); d > 0 {
+			return d.Round(time.Second)
+		}
+	}
+	return 0
+}
+
+// snippet returns the start of the body with surrounding whitespace trimmed, for including in errors.
+func snippet(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) <= maxSnippetLength {
+		return s
+	}
+	// Cutting the body short may split a multi-byte character.
+	return strings.ToValidUTF8(s[:maxSnippetLength], "") + "..."
+}
@@ -4,15 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jonboulle/clockwork"
 	"go.uber.org/zap"
 )
 
 const (
-	githubStatusURL = "https://www.githubstatus.com"
+	// GithubStatusURL is the base URL of the Github Status page.
+	GithubStatusURL = "https://www.githubstatus.com"
+
+	// GithubPageName is the default name given to the Github Status page.
+	GithubPageName = "GitHub"
+
+	// githubFauxComponentName is a faux-component that shows up in the Github API. It's hidden by
+	// default when querying the Github Status page.
+	githubFauxComponentName = "Visit www.githubstatus.com for more information"
 
 	// The number of times to retry fetching from the Github Status API on failure.
 	maxRetries = 5
@@ -35,7 +47,8 @@ const (
 	allScheduledMaintenancesEndpoint      = "/api/v2/scheduled-maintenances.json"
 )
 
-// Client is the github status client.
+// Client is a Statuspage client. By default it points to the Github Status page, but
+// it can be pointed at any page that serves the Statuspage v2 API.
 type Client interface {
 	// Summary returns the summary.
 	Summary(ctx context.Context) (SummaryResponse, error)
@@ -62,46 +75,210 @@ type Client interface {
 	AllScheduledMaintenances(ctx context.Context) (ScheduledMaintenancesResponse, error)
 }
 
+// NamedClient is a client that knows the name of the status page it's querying. Clients created by
+// NewClient implement it.
+type NamedClient interface {
+	Client
+
+	// Name returns the name of the status page the client is querying.
+	Name() string
+}
+
+// PageName returns the name of the status page the client is querying. Clients that don't implement
+// NamedClient are assumed to query the Github Status page.
+func PageName(client Client) string {
+	if named, ok := client.(NamedClient); ok {
+		return named.Name()
+	}
+	return GithubPageName
+}
+
 type client struct {
+	log        *zap.Logger
+	clock      clockwork.Clock
+	name       string
 	endpoint   string
 	httpClient *retryablehttp.Client
+	cache      *responseCache
+	inflight   coalescer
+
+	hiddenComponents map[string]bool
+	staleOnError     bool
+}
+
+// clientOptions are the settings used when constructing a client.
+type clientOptions struct {
+	clock        clockwork.Clock
+	name         string
+	baseURL      string
+	httpClient   *http.Client
+	retries      int
+	retryWaitMin time.Duration
+	retryWaitMax time.Duration
+	cacheDir     string
+	cacheTTL     time.Duration
+	staleOnError bool
+
+	hiddenComponents    []string
+	hiddenComponentsSet bool
+}
+
+// ClientOption configures a client created by NewClient.
+type ClientOption func(*clientOptions)
+
+// WithBaseURL points the client at the Statuspage hosted at the given base URL,
+// e.g. https://status.npmjs.org.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(o *clientOptions) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithPageName sets the name the client reports for its status page.
+func WithPageName(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.name = name
+	}
 }
 
-// NewClient creates a new github status client.
-func NewClient(log *zap.Logger) Client {
-	return newClient(log, githubStatusURL)
+// WithHTTPClient sets the underlying HTTP client used to make requests.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithRetryPolicy sets the number of retries and the minimum and maximum time to wait between them.
+func WithRetryPolicy(retries int, waitMin, waitMax time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.retries = retries
+		o.retryWaitMin = waitMin
+		o.retryWaitMax = waitMax
+	}
 }
 
-func newClient(log *zap.Logger, endpointURL string) *client {
+// WithCache keeps responses in the given directory so that they can be shared across processes. Cached
+// responses are used without making a request until they're older than the TTL, and are revalidated with
+// a conditional request after that. Responses are requested conditionally even without a cache directory,
+// but are then only remembered for the lifetime of the client.
+func WithCache(dir string, ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.cacheDir = dir
+		o.cacheTTL = ttl
+	}
+}
+
+// WithClock sets the clock used to decide whether cached responses are fresh.
+func WithClock(clock clockwork.Clock) ClientOption {
+	return func(o *clientOptions) {
+		o.clock = clock
+	}
+}
+
+// WithStaleOnError returns the last successfully fetched response, from memory or the cache directory given
+// by WithCache, when a response can't be fetched. The returned response is marked as stale with the time it
+// was fetched and the error. An error is only returned if there's no previous response.
+func WithStaleOnError() ClientOption {
+	return func(o *clientOptions) {
+		o.staleOnError = true
+	}
+}
+
+// WithHiddenComponents hides the components with the given names from the summary and components
+// responses. By default, the faux component on the Github Status page pointing to the page itself is
+// hidden, and no components are hidden on other pages.
+func WithHiddenComponents(names ...string) ClientOption {
+	return func(o *clientOptions) {
+		o.hiddenComponents = names
+		o.hiddenComponentsSet = true
+	}
+}
+
+// NewClient creates a new Statuspage client. With no options, the client
+// queries the Github Status page. The client also implements NamedClient and Snapshotter.
+func NewClient(log *zap.Logger, opts ...ClientOption) Client {
+	return newClient(log, opts...)
+}
+
+func newClient(log *zap.Logger, opts ...ClientOption) *client {
+	options := clientOptions{
+		clock:        clockwork.NewRealClock(),
+		name:         GithubPageName,
+		baseURL:      GithubStatusURL,
+		retries:      maxRetries,
+		retryWaitMin: retryMin,
+		retryWaitMax: retryMax,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if !options.hiddenComponentsSet && isGithubStatusURL(options.baseURL) {
+		options.hiddenComponents = []string{githubFauxComponentName}
+	}
+	hiddenComponents := map[string]bool{}
+	for _, name := range options.hiddenComponents {
+		hiddenComponents[name] = true
+	}
+
 	httpClient := retryablehttp.NewClient()
-	httpClient.RetryMax = maxRetries
-	httpClient.RetryWaitMin = retryMin
-	httpClient.RetryWaitMax = retryMax
+	httpClient.RetryMax = options.retries
+	httpClient.RetryWaitMin = options.retryWaitMin
+	httpClient.RetryWaitMax = options.retryWaitMax
+	if options.httpClient != nil {
+		httpClient.HTTPClient = options.httpClient
+	}
 	stdLog, err := zap.NewStdLogAt(log, zap.DebugLevel)
 	if err != nil {
 		panic(fmt.Sprintf("panic creating standard log: %v", err))
 	}
 	httpClient.Logger = stdLog
+	// Return the last response once the retries run out rather than a generic error so that its status can
+	// be reported.
+	httpClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
 
 	return &client{
-		endpoint:   endpointURL,
+		log:        log,
+		clock:      options.clock,
+		name:       options.name,
+		endpoint:   options.baseURL,
 		httpClient: httpClient,
+		cache:      newResponseCache(options.clock, options.cacheDir, options.cacheTTL),
+
+		hiddenComponents: hiddenComponents,
+		staleOnError:     options.staleOnError,
+	}
+}
+
+// isGithubStatusURL returns true if the base URL points at the Github Status page, regardless of its
+// scheme or a trailing slash.
+func isGithubStatusURL(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return false
 	}
+	host := strings.ToLower(u.Hostname())
+	return (host == "www.githubstatus.com" || host == "githubstatus.com") && strings.Trim(u.Path, "/") == ""
+}
+
+// Name returns the name of the status page the client is querying.
+func (c *client) Name() string {
+	return c.name
 }
 
 // Summary returns the summary.
 func (c *client) Summary(ctx context.Context) (SummaryResponse, error) {
 	var resp SummaryResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, summaryEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, summaryEndpoint, &resp); err != nil {
 		return SummaryResponse{}, err
 	}
+	resp.Components = c.withoutHiddenComponents(resp.Components)
 	return resp, nil
 }
 
 // Status returns the status.
 func (c *client) Status(ctx context.Context) (StatusResponse, error) {
 	var resp StatusResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, statusEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, statusEndpoint, &resp); err != nil {
 		return StatusResponse{}, err
 	}
 	return resp, nil
@@ -110,16 +287,32 @@ func (c *client) Status(ctx context.Context) (StatusResponse, error) {
 // Components returns the components.
 func (c *client) Components(ctx context.Context) (ComponentsResponse, error) {
 	var resp ComponentsResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, componentsEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, componentsEndpoint, &resp); err != nil {
 		return ComponentsResponse{}, err
 	}
+	resp.Components = c.withoutHiddenComponents(resp.Components)
 	return resp, nil
 }
 
+// withoutHiddenComponents returns the components without the hidden ones.
+func (c *client) withoutHiddenComponents(components []Component) []Component {
+	if len(c.hiddenComponents) == 0 {
+		return components
+	}
+
+	var filtered []Component
+	for _, component := range components {
+		if !c.hiddenComponents[component.Name] {
+			filtered = append(filtered, component)
+		}
+	}
+	return filtered
+}
+
 // UnresolvedIncidents returns the unresolved incidents.
 func (c *client) UnresolvedIncidents(ctx context.Context) (IncidentsResponse, error) {
 	var resp IncidentsResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, unresolvedIncidentsEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, unresolvedIncidentsEndpoint, &resp); err != nil {
 		return IncidentsResponse{}, err
 	}
 	return resp, nil
@@ -128,7 +321,7 @@ func (c *client) UnresolvedIncidents(ctx context.Context) (IncidentsResponse, er
 // AllIncidents returns all incidents.
 func (c *client) AllIncidents(ctx context.Context) (IncidentsResponse, error) {
 	var resp IncidentsResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, allIncidentsEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, allIncidentsEndpoint, &resp); err != nil {
 		return IncidentsResponse{}, err
 	}
 	return resp, nil
@@ -137,7 +330,7 @@ func (c *client) AllIncidents(ctx context.Context) (IncidentsResponse, error) {
 // UpcomingScheduledMaintenances returns all upcoming scheduled maintenances.
 func (c *client) UpcomingScheduledMaintenances(ctx context.Context) (ScheduledMaintenancesResponse, error) {
 	var resp ScheduledMaintenancesResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, upcomingScheduledMaintenancesEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, upcomingScheduledMaintenancesEndpoint, &resp); err != nil {
 		return ScheduledMaintenancesResponse{}, err
 	}
 	return resp, nil
@@ -146,7 +339,7 @@ func (c *client) UpcomingScheduledMaintenances(ctx context.Context) (ScheduledMa
 // ActiveScheduledMaintenances returns all active scheduled maintenances.
 func (c *client) ActiveScheduledMaintenances(ctx context.Context) (ScheduledMaintenancesResponse, error) {
 	var resp ScheduledMaintenancesResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, activeScheduledMaintenancesEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, activeScheduledMaintenancesEndpoint, &resp); err != nil {
 		return ScheduledMaintenancesResponse{}, err
 	}
 	return resp, nil
@@ -155,29 +348,136 @@ func (c *client) ActiveScheduledMaintenances(ctx context.Context) (ScheduledMain
 // AllScheduledMaintenances returns all scheduled maintenances.
 func (c *client) AllScheduledMaintenances(ctx context.Context) (ScheduledMaintenancesResponse, error) {
 	var resp ScheduledMaintenancesResponse
-	if err := getAndUnmarshal(ctx, c.httpClient, c.endpoint, allScheduledMaintenancesEndpoint, &resp); err != nil {
+	if err := getAndUnmarshal(ctx, c, allScheduledMaintenancesEndpoint, &resp); err != nil {
 		return ScheduledMaintenancesResponse{}, err
 	}
 	return resp, nil
 }
 
-func getAndUnmarshal[T any](ctx context.Context, client *retryablehttp.Client, prefix, suffix string, target T) error {
-	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s", prefix, suffix), nil)
+// sharedResponse is the body of a response from an endpoint, shared by concurrent requests for it.
+type sharedResponse struct {
+	body json.RawMessage
+
+	// stale is set if the body is the last fetched response rather than a fresh one.
+	stale *Staleness
+}
+
+// getAndUnmarshal gets the response from the endpoint. Concurrent requests for the same endpoint
+// share a single request, but each decodes the body itself so that they don't share any memory.
+func getAndUnmarshal[T any](ctx context.Context, c *client, suffix string, target *T) error {
+	endpoint := fmt.Sprintf("%s%s", c.endpoint, suffix)
+
+	shared, err := c.inflight.do(ctx, endpoint, func(ctx context.Context) (any, error) {
+		var resp T
+		return fetchOrStale(ctx, c, endpoint, &resp)
+	})
 	if err != nil {
 		return err
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
+	resp := shared.(sharedResponse)
+	if err := unmarshal(endpoint, "", resp.body, target); err != nil {
 		return err
 	}
+	if s, ok := any(target).(staler); ok && resp.stale != nil {
+		stale := *resp.stale
+		s.setStale(&stale)
+	}
+	return nil
+}
+
+// fetchOrStale fetches the response from the endpoint, falling back to the last fetched one if the client
+// serves stale responses.
+func fetchOrStale[T any](ctx context.Context, c *client, endpoint string, target *T) (sharedResponse, error) {
+	body, err := fetch(ctx, c, endpoint, target)
+	if err == nil {
+		return sharedResponse{body: body}, nil
+	}
+	if !c.staleOnError {
+		return sharedResponse{}, err
+	}
+
+	cached, ok := c.cache.get(endpoint)
+	if !ok {
+		return sharedResponse{}, err
+	}
+	c.log.With(zap.Error(err), zap.String("endpoint", endpoint)).Warn("error fetching response, using the last fetched one")
+	return sharedResponse{body: cached.Body, stale: newStaleness(c.clock.Now(), cached.Fetched, err)}, nil
+}
+
+// fetch gets the response from the endpoint, or from the cache if it's fresh there, and decodes it. The
+// decoded body is returned.
+func fetch(ctx context.Context, c *client, endpoint string, target any) ([]byte, error) {
+	cached, isCached := c.cache.get(endpoint)
+	if isCached && c.cache.fresh(cached) {
+		return cached.Body, unmarshal(endpoint, "", cached.Body, target)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if isCached {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
 
 	defer resp.Body.Close()
 
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(target); err != nil {
-		return err
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from %s: %w", endpoint, err)
 	}
 
+	if isCached && resp.StatusCode == http.StatusNotModified {
+		cached.Fetched = c.clock.Now()
+		c.cacheResponse(endpoint, cached)
+		return cached.Body, unmarshal(endpoint, "", cached.Body, target)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newResponseError(c.clock, endpoint, resp, body)
+	}
+
+	if err := unmarshal(endpoint, resp.Header.Get("Content-Type"), body, target); err != nil {
+		return nil, err
+	}
+
+	// Responses without validators are only worth keeping while they're fresh or to fall back to when fetching fails.
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag != "" || lastModified != "" || c.cache.ttl > 0 || c.cache.dir != "" || c.staleOnError {
+		c.cacheResponse(endpoint, cachedResponse{ETag: etag, LastModified: lastModified, Fetched: c.clock.Now(), Body: body})
+	}
+
+	return body, nil
+}
+
+// unmarshal decodes the body of a response from the endpoint.
+func unmarshal(endpoint, contentType string, body []byte, target any) error {
+	if err := json.Unmarshal(body, target); err != nil {
+		return &DecodeError{
+			Endpoint:    endpoint,
+			ContentType: contentType,
+			Body:        snippet(body),
+			Err:         err,
+		}
+	}
 	return nil
 }
+
+// cacheResponse caches the response from the endpoint. The cache is only an optimization, so failing to
+// write to it is logged rather than failing the request.
+func (c *client) cacheResponse(endpoint string, resp cachedResponse) {
+	if err := c.cache.put(endpoint, resp); err != nil {
+		c.log.With(zap.Error(err), zap.String("endpoint", endpoint)).Warn("error caching response")
+	}
+}
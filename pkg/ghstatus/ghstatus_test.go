@@ -1,11 +1,24 @@
 package ghstatus
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -22,7 +35,509 @@ func TestUnmarshalActualResponse(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Len(t, summary.Components, 11)
+	require.Equal(t, "8l4ygp009s5s", summary.Components[0].ID)
 	require.Len(t, summary.Incidents, 1)
 	require.Len(t, summary.Incidents[0].IncidentUpdates, 15)
+	require.Equal(t, []string{"Git Operations", "API Requests", "Pull Requests", "Actions", "Pages", "Codespaces"},
+		ComponentNames(summary.Incidents[0].Components))
+	require.False(t, summary.Incidents[0].ResolvedAt.Valid)
+	require.NoError(t, summary.Validate())
 	require.Empty(t, summary.ScheduledMaintenances)
 }
+
+func TestUnmarshalAffectedComponents(t *testing.T) {
+	server, client := NewTestServerAndClient(t)
+	server.SetSummaryRaw([]byte(`{
+		"incidents": [{
+			"id": "incident",
+			"components": [{"id": "actions", "name": "Actions", "status": "partial_outage"}],
+			"incident_updates": [{
+				"id": "update",
+				"affected_components": [
+					{"code": "actions", "name": "Actions", "old_status": "operational", "new_status": "partial_outage"}
+				]
+			}]
+		}],
+		"scheduled_maintenances": [{
+			"id": "maintenance",
+			"components": [{"id": "pages", "name": "Pages", "status": "under_maintenance"}]
+		}]
+	}`))
+
+	summary, err := client.Summary(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, summary.Incidents, 1)
+	require.Equal(t, []string{"Actions"}, ComponentNames(summary.Incidents[0].Components))
+	require.Equal(t, []AffectedComponent{
+		{Code: "actions", Name: "Actions", OldStatus: Operational, NewStatus: PartialOutage},
+	}, summary.Incidents[0].IncidentUpdates[0].AffectedComponents)
+
+	require.Len(t, summary.ScheduledMaintenances, 1)
+	require.Equal(t, []string{"Pages"}, ComponentNames(summary.ScheduledMaintenances[0].Components))
+}
+
+func TestClientOptions(t *testing.T) {
+	server, _ := NewTestServerAndClient(t)
+	server.SetSummary(t, SummaryResponse{Page: Page{Name: "npm"}})
+
+	defaultClient := NewClient(zap.NewNop())
+	require.Equal(t, GithubPageName, PageName(defaultClient))
+	require.Equal(t, GithubPageName, PageName(struct{ Client }{defaultClient}))
+
+	var requests int
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	client := NewClient(zap.NewNop(),
+		WithBaseURL(server.URL+"/"),
+		WithPageName("npm"),
+		WithHTTPClient(httpClient),
+		WithRetryPolicy(0, time.Millisecond, time.Millisecond),
+	)
+	require.Equal(t, "npm", PageName(client))
+
+	summary, err := client.Summary(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "npm", summary.Page.Name)
+	require.Equal(t, 1, requests)
+}
+
+func TestHiddenComponents(t *testing.T) {
+	components := []Component{{ID: "actions", Name: "Actions"}, {ID: "faux", Name: githubFauxComponentName}}
+	httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := json.Marshal(SummaryResponse{Components: components})
+		require.NoError(t, err)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(body)), Request: req}, nil
+	})}
+	ctx := context.Background()
+
+	// The faux component is hidden on the Github Status page by default.
+	summary, err := NewClient(zap.NewNop(), WithHTTPClient(httpClient)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Actions"}, ComponentNames(summary.Components))
+
+	summary, err = NewClient(zap.NewNop(), WithBaseURL("http://githubstatus.com/"), WithHTTPClient(httpClient)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Actions"}, ComponentNames(summary.Components))
+
+	// But not on other pages.
+	summary, err = NewClient(zap.NewNop(), WithBaseURL("https://status.example.com"), WithHTTPClient(httpClient)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, components, summary.Components)
+
+	summary, err = NewClient(zap.NewNop(), WithHTTPClient(httpClient), WithHiddenComponents("Actions")).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{githubFauxComponentName}, ComponentNames(summary.Components))
+}
+
+func TestResponseErrors(t *testing.T) {
+	respond := func(statusCode int, header http.Header, body string) Client {
+		httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if header == nil {
+				header = http.Header{}
+			}
+			return &http.Response{
+				StatusCode: statusCode,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		})}
+		return NewClient(zap.NewNop(), WithBaseURL("https://status.example.com"), WithHTTPClient(httpClient),
+			WithRetryPolicy(0, time.Millisecond, time.Millisecond))
+	}
+	ctx := context.Background()
+
+	_, err := respond(http.StatusServiceUnavailable, http.Header{"Content-Type": {"text/html"}}, "<html>Unicorn!</html>").Summary(ctx)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, "https://status.example.com/api/v2/summary.json", statusErr.Endpoint)
+	require.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	require.Equal(t, "<html>Unicorn!</html>", statusErr.Body)
+	require.True(t, Temporary(err))
+
+	_, err = respond(http.StatusNotFound, nil, `{"error": "not found"}`).Summary(ctx)
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	require.False(t, Temporary(err))
+
+	_, err = respond(http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}}, "").Summary(ctx)
+	var rateLimitErr *RateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+	require.Equal(t, 30*time.Second, rateLimitErr.RetryAfter)
+	require.ErrorAs(t, err, &statusErr)
+	require.True(t, Temporary(err))
+
+	// Retry-After dates are relative to the client's clock. They only have whole seconds, so the clock starts on one.
+	clock := clockwork.NewFakeClockAt(time.Date(2023, time.May, 10, 13, 0, 0, 0, time.UTC))
+	require.Equal(t, 2*time.Minute, retryAfter(clock, clock.Now().Add(2*time.Minute).UTC().Format(http.TimeFormat)))
+	require.Zero(t, retryAfter(clock, clock.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)))
+
+	_, err = respond(http.StatusOK, http.Header{"Content-Type": {"text/html"}}, "<html>"+strings.Repeat("a", 1000)+"</html>").Summary(ctx)
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	require.Equal(t, "text/html", decodeErr.ContentType)
+	require.Len(t, decodeErr.Body, maxSnippetLength+len("..."))
+	require.True(t, Temporary(err))
+
+	require.False(t, Temporary(context.Canceled))
+}
+
+func TestTemporaryTransportErrors(t *testing.T) {
+	newClient := func(transport http.RoundTripper) Client {
+		return NewClient(zap.NewNop(), WithBaseURL("https://status.example.com"), WithHTTPClient(&http.Client{Transport: transport}),
+			WithRetryPolicy(0, time.Millisecond, time.Millisecond))
+	}
+	failWith := func(err error) Client {
+		return newClient(roundTripperFunc(func(req *http.Request) (*http.Response, error) { return nil, err }))
+	}
+	ctx := context.Background()
+
+	_, err := failWith(&net.DNSError{Err: "no such host", Name: "status.example.com", IsNotFound: true}).Summary(ctx)
+	require.Error(t, err)
+	require.True(t, Temporary(err))
+
+	_, err = failWith(io.ErrUnexpectedEOF).Summary(ctx)
+	require.True(t, Temporary(err))
+
+	_, err = failWith(errors.New("unsupported protocol scheme")).Summary(ctx)
+	require.Error(t, err)
+	require.False(t, Temporary(err))
+
+	// Connections that are refused.
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	_, err = NewClient(zap.NewNop(), WithBaseURL(server.URL), WithRetryPolicy(0, time.Millisecond, time.Millisecond)).Summary(ctx)
+	require.Error(t, err)
+	require.True(t, Temporary(err))
+
+	// Requests that time out.
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-r.Context().Done() }))
+	t.Cleanup(hanging.Close)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = NewClient(zap.NewNop(), WithBaseURL(hanging.URL), WithRetryPolicy(0, time.Millisecond, time.Millisecond)).Summary(timeoutCtx)
+	require.Error(t, err)
+	require.True(t, Temporary(err))
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = NewClient(zap.NewNop(), WithBaseURL(hanging.URL), WithRetryPolicy(0, time.Millisecond, time.Millisecond)).Summary(canceledCtx)
+	require.Error(t, err)
+	require.False(t, Temporary(err))
+
+	_, err = NewClient(zap.NewNop(), WithBaseURL("://status.example.com")).Summary(ctx)
+	require.Error(t, err)
+	require.False(t, Temporary(err))
+}
+
+func TestConditionalRequestsAndCache(t *testing.T) {
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, err := w.Write([]byte(`{"page": {"name": "npm"}}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+
+	// Without a cache directory, responses are revalidated with conditional requests.
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+	for i := 0; i < 2; i++ {
+		summary, err := client.Summary(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "npm", summary.Page.Name)
+	}
+	require.Equal(t, 2, requests)
+	require.Equal(t, 1, notModified)
+
+	// Fresh responses in the cache directory are shared between clients without making requests.
+	dir := t.TempDir()
+	clock := clockwork.NewFakeClock()
+	requests, notModified = 0, 0
+	summary, err := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithCache(dir, time.Hour), WithClock(clock)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "npm", summary.Page.Name)
+	clock.Advance(59 * time.Minute)
+	summary, err = NewClient(zap.NewNop(), WithBaseURL(server.URL), WithCache(dir, time.Hour), WithClock(clock)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "npm", summary.Page.Name)
+	require.Equal(t, 1, requests)
+
+	// Once the TTL has passed, they're revalidated.
+	clock.Advance(time.Minute)
+	summary, err = NewClient(zap.NewNop(), WithBaseURL(server.URL), WithCache(dir, time.Hour), WithClock(clock)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "npm", summary.Page.Name)
+	require.Equal(t, 2, requests)
+	require.Equal(t, 1, notModified)
+
+	// Without a TTL, responses in the cache directory are always revalidated.
+	summary, err = NewClient(zap.NewNop(), WithBaseURL(server.URL), WithCache(dir, 0), WithClock(clock)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "npm", summary.Page.Name)
+	require.Equal(t, 3, requests)
+	require.Equal(t, 2, notModified)
+}
+
+func TestStaleOnError(t *testing.T) {
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, err := w.Write([]byte(`{"page": {"name": "npm"}}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	summary, err := NewClient(zap.NewNop(), WithBaseURL(server.URL), WithCache(dir, 0)).Summary(ctx)
+	require.NoError(t, err)
+	require.Nil(t, summary.Stale)
+
+	failing = true
+	_, err = NewClient(zap.NewNop(), WithBaseURL(server.URL), WithCache(dir, 0),
+		WithRetryPolicy(0, time.Millisecond, time.Millisecond)).Summary(ctx)
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+
+	// The last fetched response is returned from the cache directory, marked as stale.
+	summary, err = NewClient(zap.NewNop(), WithBaseURL(server.URL), WithCache(dir, 0), WithStaleOnError(),
+		WithRetryPolicy(0, time.Millisecond, time.Millisecond)).Summary(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "npm", summary.Page.Name)
+	require.NotNil(t, summary.Stale)
+	require.False(t, summary.Stale.FetchedAt.IsZero())
+	require.Contains(t, summary.Stale.Error, "503")
+
+	// Without a previous response, the error is returned.
+	_, err = NewClient(zap.NewNop(), WithBaseURL(server.URL), WithStaleOnError(),
+		WithRetryPolicy(0, time.Millisecond, time.Millisecond)).Status(ctx)
+	require.ErrorAs(t, err, &statusErr)
+}
+
+func TestSnapshot(t *testing.T) {
+	server, client := NewTestServerAndClient(t)
+	server.SetSummary(t, SummaryResponse{Page: Page{Name: "npm"}})
+	server.set(&server.UnresolvedIncidents, []byte(`{"incidents": [{"id": "incident"}]}`))
+	server.set(&server.UpcomingScheduledMaintenances, []byte(`{"scheduled_maintenances": [{"id": "maintenance"}]}`))
+	ctx := context.Background()
+
+	snapshot, err := client.(Snapshotter).Snapshot(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "npm", snapshot.Summary.Page.Name)
+	require.Equal(t, "incident", snapshot.UnresolvedIncidents.Incidents[0].ID)
+	require.Equal(t, "maintenance", snapshot.UpcomingScheduledMaintenances.ScheduledMaintenances[0].ID)
+	require.Nil(t, snapshot.Status)
+
+	// Resources that can't be fetched are left out and their errors returned.
+	snapshot, err = client.(Snapshotter).Snapshot(ctx, ResourceSummary, ResourceStatus, Resource("unknown"))
+	require.ErrorContains(t, err, "error fetching status")
+	require.ErrorContains(t, err, `error fetching unknown: unknown resource "unknown"`)
+	require.Equal(t, "npm", snapshot.Summary.Page.Name)
+	require.Nil(t, snapshot.Status)
+}
+
+func TestCoalescesConcurrentRequests(t *testing.T) {
+	var requests atomic.Int32
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		received <- struct{}{}
+		<-release
+		_, err := w.Write([]byte(`{"page": {"name": "npm"}, "components": [{"id": "actions", "name": "Actions"}]}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(zap.NewNop(), WithBaseURL(server.URL))
+
+	// The first caller gives up while its request is in flight.
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := client.Summary(firstCtx)
+		firstErr <- err
+	}()
+	<-received
+
+	const callers = 4
+	results := make(chan SummaryResponse, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			summary, err := client.Summary(context.Background())
+			require.NoError(t, err)
+			results <- summary
+		}()
+	}
+
+	// Give the other callers time to join the request in flight before it's abandoned by the first one.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-firstErr, context.Canceled)
+	close(release)
+
+	// The request carries on for the callers still waiting for it, and each gets its own copy of the response.
+	first := <-results
+	require.Equal(t, "npm", first.Page.Name)
+	first.Components[0].Name = "Changed"
+	for i := 1; i < callers; i++ {
+		summary := <-results
+		require.Equal(t, "npm", summary.Page.Name)
+		require.Equal(t, "Actions", summary.Components[0].Name)
+	}
+	require.EqualValues(t, 1, requests.Load())
+}
+
+func TestCoalescedCallContext(t *testing.T) {
+	type key struct{}
+	var c coalescer
+	started := make(chan context.Context, 1)
+	fn := func(ctx context.Context) (any, error) {
+		started <- ctx
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), time.Hour)
+	t.Cleanup(cancel)
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.do(ctx, "summary", fn)
+		errs <- err
+	}()
+
+	// The call has the values and the deadline of the caller that made it.
+	callCtx := <-started
+	require.Equal(t, "value", callCtx.Value(key{}))
+	deadline, ok := callCtx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+
+	// A caller with an earlier deadline brings the deadline of the call forward.
+	soonCtx, cancelSoon := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	t.Cleanup(cancelSoon)
+	go func() {
+		_, err := c.do(soonCtx, "summary", fn)
+		errs <- err
+	}()
+	require.Eventually(t, func() bool {
+		deadline, _ := callCtx.Deadline()
+		return deadline.Before(time.Now().Add(time.Minute))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.ErrorIs(t, <-errs, context.DeadlineExceeded)
+	require.ErrorIs(t, <-errs, context.DeadlineExceeded)
+	require.ErrorIs(t, callCtx.Err(), context.DeadlineExceeded)
+}
+
+func TestComponentTree(t *testing.T) {
+	components := []Component{
+		{ID: "packages", Name: "Packages", GroupID: "cicd", Position: 3},
+		{ID: "cicd", Name: "CI/CD", Group: true, Components: []string{"actions", "packages"}, Position: 2},
+		{ID: "actions", Name: "Actions", Position: 2},
+		{ID: "git", Name: "Git Operations", Position: 1},
+	}
+
+	require.Equal(t, []ComponentNode{
+		{Component: components[3]},
+		{Component: components[1], Children: []ComponentNode{
+			{Component: components[2]},
+			{Component: components[0]},
+		}},
+	}, ComponentTree(components))
+
+	group, ok := ComponentGroup(components, components[2])
+	require.True(t, ok)
+	require.Equal(t, "CI/CD", group.Name)
+
+	_, ok = ComponentGroup(components, components[3])
+	require.False(t, ok)
+
+	require.Equal(t, []Component{components[2], components[0]}, GroupMembers(components, components[1]))
+}
+
+func TestNullTime(t *testing.T) {
+	resolvedAt := time.Date(2023, 5, 10, 13, 0, 0, 0, time.UTC)
+	incidents := []Incident{
+		{ID: "unresolved", IncidentUpdates: []IncidentUpdate{}},
+		{ID: "resolved", IncidentUpdates: []IncidentUpdate{}, ResolvedAt: NewNullTime(resolvedAt)},
+	}
+	require.Equal(t, "—", incidents[0].ResolvedAt.String())
+
+	data, err := json.Marshal(incidents)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"resolved_at":null`)
+	require.Contains(t, string(data), `"resolved_at":"2023-05-10T13:00:00Z"`)
+
+	var decoded []Incident
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, incidents, decoded)
+
+	data, err = yaml.Marshal(incidents)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "resolved_at: null")
+
+	decoded = nil
+	require.NoError(t, yaml.Unmarshal(data, &decoded))
+	require.Equal(t, incidents, decoded)
+
+	// Zero timestamps from older encodings are treated as not set.
+	var zero NullTime
+	require.NoError(t, json.Unmarshal([]byte(`"0001-01-01T00:00:00Z"`), &zero))
+	require.False(t, zero.Valid)
+}
+
+func TestSeverity(t *testing.T) {
+	require.True(t, MajorOutage.Severity().AtLeast(PartialOutage.Severity()))
+	require.True(t, PartialOutage.Severity().AtLeast(SeverityMajor))
+	require.False(t, DegradedPerformance.Severity().AtLeast(SeverityMajor))
+	require.False(t, UnderMaintenance.Severity().AtLeast(SeverityMinor))
+	require.Equal(t, Critical.Severity(), MajorOutage.Severity())
+	require.Equal(t, SeverityUnknown, ComponentStatus("on_fire").Severity())
+
+	require.Equal(t, SeverityMajor, Incident{Status: Identified, Impact: Major}.Severity())
+	require.Equal(t, SeverityNone, Incident{Status: Resolved, Impact: Major}.Severity())
+	require.Equal(t, SeverityMaintenance, ScheduledMaintenance{Status: InProgress}.Severity())
+	require.True(t, Investigating.Severity().AtLeast(Monitoring.Severity()))
+
+	severity, err := ParseSeverity("partial_outage")
+	require.NoError(t, err)
+	require.Equal(t, SeverityMajor, severity)
+	severity, err = ParseSeverity("critical")
+	require.NoError(t, err)
+	require.Equal(t, SeverityCritical, severity)
+	_, err = ParseSeverity("bad")
+	require.Error(t, err)
+
+	status, err := ParseComponentStatus("under_maintenance")
+	require.NoError(t, err)
+	require.Equal(t, UnderMaintenance, status)
+	_, err = ParseIndicator("on_fire")
+	require.Error(t, err)
+
+	require.NoError(t, SummaryResponse{
+		Status:     Status{Indicator: Maintenance},
+		Components: []Component{{Name: "Actions", Status: UnderMaintenance}},
+	}.Validate())
+	require.Error(t, SummaryResponse{
+		Status:     Status{Indicator: None},
+		Components: []Component{{Name: "Actions", Status: "on_fire"}},
+	}.Validate())
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,99 @@
+package ghstatus
+
+import "sort"
+
+// ComponentNode is a component in the component tree along with its children.
+type ComponentNode struct {
+	// Component is the component.
+	Component Component `json:"component" yaml:"component"`
+
+	// Children are the components in the group. This is only populated for groups.
+	Children []ComponentNode `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// ComponentTree arranges the given components into a tree. Groups and components that don't belong
+// to a group are at the root of the tree, while the members of each group are its children. Siblings
+// are ordered by their position on the page.
+func ComponentTree(components []Component) []ComponentNode {
+	var roots []Component
+	children := map[string][]Component{}
+	for _, component := range components {
+		if group, ok := ComponentGroup(components, component); ok {
+			children[group.ID] = append(children[group.ID], component)
+			continue
+		}
+		roots = append(roots, component)
+	}
+
+	var buildNodes func([]Component) []ComponentNode
+	buildNodes = func(siblings []Component) []ComponentNode {
+		if len(siblings) == 0 {
+			return nil
+		}
+		sortByPosition(siblings)
+		nodes := make([]ComponentNode, 0, len(siblings))
+		for _, component := range siblings {
+			nodes = append(nodes, ComponentNode{
+				Component: component,
+				Children:  buildNodes(children[component.ID]),
+			})
+		}
+		return nodes
+	}
+
+	return buildNodes(roots)
+}
+
+// ComponentGroup returns the group the given component belongs to, if any. Membership is determined by the
+// component's group ID or, failing that, by the group's list of member components.
+func ComponentGroup(components []Component, component Component) (Component, bool) {
+	if component.GroupID != "" {
+		for _, candidate := range components {
+			if candidate.ID == component.GroupID && candidate.ID != component.ID {
+				return candidate, true
+			}
+		}
+		return Component{}, false
+	}
+
+	for _, candidate := range components {
+		if !candidate.Group || candidate.ID == component.ID {
+			continue
+		}
+		for _, memberID := range candidate.Components {
+			if memberID == component.ID {
+				return candidate, true
+			}
+		}
+	}
+
+	return Component{}, false
+}
+
+// GroupMembers returns the components that belong to the given group, ordered by their position on the page.
+func GroupMembers(components []Component, group Component) []Component {
+	var members []Component
+	for _, component := range components {
+		if g, ok := ComponentGroup(components, component); ok && g.ID == group.ID {
+			members = append(members, component)
+		}
+	}
+	sortByPosition(members)
+	return members
+}
+
+// ComponentNames returns the names of the given components.
+func ComponentNames(components []Component) []string {
+	var names []string
+	for _, component := range components {
+		names = append(names, component.Name)
+	}
+	return names
+}
+
+// sortByPosition sorts the components by their position on the page.
+func sortByPosition(components []Component) {
+	sort.SliceStable(components, func(i, j int) bool {
+		return components[i].Position < components[j].Position
+	})
+}
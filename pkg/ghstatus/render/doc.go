@@ -2,5 +2,5 @@
 // various formats.
 //
 // This package contains functions that allow for easy display of the Github Status
-// responses in a human readable table, YAML, or JSON.
+// responses, as well as the monitor's change journal, in a human readable table, YAML, or JSON.
 package render
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/journal"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +18,8 @@ const (
 	YAML Format = iota
 	JSON
 	Table
+	// Tree is the table format with components arranged as a tree of groups and their members.
+	Tree
 )
 
 // FormatFromString returns a Format from a string descriptor.
@@ -27,6 +31,8 @@ func FormatFromString(format string) (Format, error) {
 		return JSON, nil
 	case "table":
 		return Table, nil
+	case "tree":
+		return Tree, nil
 	}
 
 	return 0, fmt.Errorf("unrecognized format string %T", format)
@@ -50,21 +56,73 @@ func Render(target any, format Format) (string, error) {
 		}
 		return buf.String(), nil
 	case Table:
-		switch t := target.(type) {
-		case ghstatus.SummaryResponse:
-			return summaryResponseWithTables(t), nil
-		case ghstatus.StatusResponse:
-			return statusResponseWithTables(t), nil
-		case ghstatus.ComponentsResponse:
-			return componentsResponseWithTables(t), nil
-		case ghstatus.IncidentsResponse:
-			return incidentsResponseWithTables(t), nil
-		case ghstatus.ScheduledMaintenancesResponse:
-			return scheduledMaintenancesResponseWithTables(t), nil
-		default:
-			return "", fmt.Errorf("type %T does not support table rendering", target)
+		out, err := tables(target)
+		if err != nil {
+			return "", err
 		}
+		return staleBanner(target) + out, nil
+	case Tree:
+		out, err := tree(target)
+		if err != nil {
+			return "", err
+		}
+		return staleBanner(target) + out, nil
 	default:
 		return "", fmt.Errorf("unrecognized format: %d", format)
 	}
 }
+
+// tables renders the target with tables.
+func tables(target any) (string, error) {
+	switch t := target.(type) {
+	case ghstatus.SummaryResponse:
+		return summaryResponseWithTables(t), nil
+	case ghstatus.StatusResponse:
+		return statusResponseWithTables(t), nil
+	case ghstatus.ComponentsResponse:
+		return componentsResponseWithTables(t), nil
+	case ghstatus.IncidentsResponse:
+		return incidentsResponseWithTables(t), nil
+	case ghstatus.ScheduledMaintenancesResponse:
+		return scheduledMaintenancesResponseWithTables(t), nil
+	case []journal.Entry:
+		return journalEntriesWithTables(t), nil
+	default:
+		return "", fmt.Errorf("type %T does not support table rendering", target)
+	}
+}
+
+// tree renders the target with tables, showing components as a tree.
+func tree(target any) (string, error) {
+	switch t := target.(type) {
+	case ghstatus.SummaryResponse:
+		return summaryResponseWithTree(t), nil
+	case ghstatus.ComponentsResponse:
+		return componentsResponseWithTree(t), nil
+	default:
+		return "", fmt.Errorf("type %T does not support tree rendering", target)
+	}
+}
+
+// staleBanner returns a banner warning that the target is stale, or nothing if it isn't.
+func staleBanner(target any) string {
+	var stale *ghstatus.Staleness
+	switch t := target.(type) {
+	case ghstatus.SummaryResponse:
+		stale = t.Stale
+	case ghstatus.StatusResponse:
+		stale = t.Stale
+	case ghstatus.ComponentsResponse:
+		stale = t.Stale
+	case ghstatus.IncidentsResponse:
+		stale = t.Stale
+	case ghstatus.ScheduledMaintenancesResponse:
+		stale = t.Stale
+	}
+	if stale == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("Stale as of %s (%s ago), the status page couldn't be reached: %s\n\n",
+		stale.FetchedAt.Format(time.RFC3339), stale.Age, stale.Error)
+}
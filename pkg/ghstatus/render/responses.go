@@ -2,12 +2,24 @@ package render
 
 import (
 	"bytes"
+	"io"
 
 	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/journal"
 )
 
 // summaryResponseWithTables will render the summary response with tables.
 func summaryResponseWithTables(s ghstatus.SummaryResponse) string {
+	return summaryResponse(s, componentsTable)
+}
+
+// summaryResponseWithTree will render the summary response with tables, showing the components as a tree.
+func summaryResponseWithTree(s ghstatus.SummaryResponse) string {
+	return summaryResponse(s, componentsTreeTable)
+}
+
+// summaryResponse will render the summary response with tables, using the given function to render the components.
+func summaryResponse(s ghstatus.SummaryResponse, componentsTable func(io.Writer, []ghstatus.Component)) string {
 	buf := bytes.NewBuffer(nil)
 
 	buf.WriteString("# Status\n\n")
@@ -54,6 +66,16 @@ func componentsResponseWithTables(c ghstatus.ComponentsResponse) string {
 	return buf.String()
 }
 
+// componentsResponseWithTree will render the components response as a tree.
+func componentsResponseWithTree(c ghstatus.ComponentsResponse) string {
+	buf := bytes.NewBuffer(nil)
+
+	buf.WriteString("# Components\n\n")
+	componentsTreeTable(buf, c.Components)
+
+	return buf.String()
+}
+
 // incidentsResponseWithTables will render an incidents response with tables.
 func incidentsResponseWithTables(i ghstatus.IncidentsResponse) string {
 	buf := bytes.NewBuffer(nil)
@@ -73,3 +95,13 @@ func scheduledMaintenancesResponseWithTables(s ghstatus.ScheduledMaintenancesRes
 
 	return buf.String()
 }
+
+// journalEntriesWithTables will render journal entries with tables.
+func journalEntriesWithTables(entries []journal.Entry) string {
+	buf := bytes.NewBuffer(nil)
+
+	buf.WriteString("# History\n\n")
+	journalEntriesTable(buf, entries)
+
+	return buf.String()
+}
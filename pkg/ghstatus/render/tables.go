@@ -2,8 +2,10 @@ package render
 
 import (
 	"io"
+	"strings"
 
 	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/journal"
 	"github.com/olekukonko/tablewriter"
 )
 
@@ -23,28 +25,71 @@ func componentsTable(w io.Writer, components []ghstatus.Component) {
 	table.Render()
 }
 
+// componentsTreeTable writes a rendered table of components to the writer, with the members
+// of each group listed beneath it.
+func componentsTreeTable(w io.Writer, components []ghstatus.Component) {
+	table := newTable(w, "Name", "Description", "Status", "Updated")
+
+	appendComponent := func(prefix string, c ghstatus.Component) {
+		table.Append([]string{prefix + c.Name, c.Description, string(c.Status), c.UpdatedAt.String()})
+	}
+
+	var appendChildren func(nodes []ghstatus.ComponentNode, prefix string)
+	appendChildren = func(nodes []ghstatus.ComponentNode, prefix string) {
+		for i, node := range nodes {
+			branch, continuation := "├─ ", "│  "
+			if i == len(nodes)-1 {
+				branch, continuation = "└─ ", "   "
+			}
+			appendComponent(prefix+branch, node.Component)
+			appendChildren(node.Children, prefix+continuation)
+		}
+	}
+
+	for _, root := range ghstatus.ComponentTree(components) {
+		appendComponent("", root.Component)
+		appendChildren(root.Children, "")
+	}
+	table.Render()
+}
+
 // incidentsTable writes a rendered table of incidents to the writer.
 func incidentsTable(w io.Writer, incidents []ghstatus.Incident) {
-	table := newTable(w, "Name", "Status", "Updated", "Latest Update")
+	table := newTable(w, "Name", "Status", "Components", "Updated", "Latest Update")
 	for _, i := range incidents {
 		lastUpdate := ""
 		if len(i.IncidentUpdates) > 0 {
 			lastUpdate = i.IncidentUpdates[0].Body
 		}
-		table.Append([]string{string(i.Name), string(i.Status), i.UpdatedAt.String(), lastUpdate})
+		table.Append([]string{string(i.Name), string(i.Status), componentNames(i.Components), i.UpdatedAt.String(), lastUpdate})
 	}
 	table.Render()
 }
 
 // scheduledMaintenancesTable writes a rendered table of scheduled maintenances to the writer.
 func scheduledMaintenancesTable(w io.Writer, scheduledMaintenances []ghstatus.ScheduledMaintenance) {
-	table := newTable(w, "Name", "Impact", "Status", "Scheduled For", "Scheduled Until")
+	table := newTable(w, "Name", "Impact", "Status", "Components", "Scheduled For", "Scheduled Until")
 	for _, s := range scheduledMaintenances {
-		table.Append([]string{string(s.Name), string(s.Impact), string(s.Impact), s.ScheduledFor.String(), s.ScheduledUntil.String()})
+		table.Append([]string{string(s.Name), string(s.Impact), string(s.Status), componentNames(s.Components),
+			s.ScheduledFor.String(), s.ScheduledUntil.String()})
+	}
+	table.Render()
+}
+
+// journalEntriesTable writes a rendered table of journal entries to the writer.
+func journalEntriesTable(w io.Writer, entries []journal.Entry) {
+	table := newTable(w, "Time", "Page", "Kind", "Name", "Change")
+	for _, e := range entries {
+		table.Append([]string{e.Time.String(), e.Page, string(e.Kind), e.Name, e.Summary()})
 	}
 	table.Render()
 }
 
+// componentNames returns the names of the components as a comma separated list.
+func componentNames(components []ghstatus.Component) string {
+	return strings.Join(ghstatus.ComponentNames(components), ", ")
+}
+
 // newTable returns a markdown compatible table generator.
 func newTable(w io.Writer, headers ...string) *tablewriter.Table {
 	table := tablewriter.NewWriter(w)
@@ -16,6 +16,10 @@ type SummaryResponse struct {
 
 	// ScheduledMaintenances is a list of scheduled maintenances.
 	ScheduledMaintenances []ScheduledMaintenance `json:"scheduled_maintenances"`
+
+	// Stale is set if the response couldn't be fetched and the last successfully fetched response was
+	// returned instead. See WithStaleOnError.
+	Stale *Staleness `json:"stale,omitempty" yaml:"stale,omitempty"`
 }
 
 // StatusResponse is the response from the status endpoint.
@@ -25,6 +29,10 @@ type StatusResponse struct {
 
 	// Status is the current github status.
 	Status Status `json:"status"`
+
+	// Stale is set if the response couldn't be fetched and the last successfully fetched response was
+	// returned instead. See WithStaleOnError.
+	Stale *Staleness `json:"stale,omitempty" yaml:"stale,omitempty"`
 }
 
 // ComponentsResponse is the response from the components endpoint.
@@ -34,6 +42,10 @@ type ComponentsResponse struct {
 
 	// Components are a list of components.
 	Components []Component `json:"components"`
+
+	// Stale is set if the response couldn't be fetched and the last successfully fetched response was
+	// returned instead. See WithStaleOnError.
+	Stale *Staleness `json:"stale,omitempty" yaml:"stale,omitempty"`
 }
 
 // IncidentsResponse is the response from one of the incident endpoints.
@@ -43,6 +55,10 @@ type IncidentsResponse struct {
 
 	// Incidents is a list of incidents.
 	Incidents []Incident `json:"incidents"`
+
+	// Stale is set if the response couldn't be fetched and the last successfully fetched response was
+	// returned instead. See WithStaleOnError.
+	Stale *Staleness `json:"stale,omitempty" yaml:"stale,omitempty"`
 }
 
 // ScheduledMaintenancesResponse is the response from one of the scheduled maintenance endpoints.
@@ -52,4 +68,8 @@ type ScheduledMaintenancesResponse struct {
 
 	// ScheduledMaintenances is a list of scheduled maintenances.
 	ScheduledMaintenances []ScheduledMaintenance `json:"scheduled_maintenances"`
+
+	// Stale is set if the response couldn't be fetched and the last successfully fetched response was
+	// returned instead. See WithStaleOnError.
+	Stale *Staleness `json:"stale,omitempty" yaml:"stale,omitempty"`
 }
@@ -0,0 +1,276 @@
+package ghstatus
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Severity is a comparable measure of how severe an indicator, component status or incident status is.
+// Severities are ordered, so thresholds such as "major or worse" can be expressed with AtLeast.
+type Severity int
+
+const (
+	// SeverityUnknown is the severity of values that aren't recognized. It's ordered below every known severity.
+	SeverityUnknown Severity = iota
+	SeverityNone
+	SeverityMaintenance
+	SeverityMinor
+	SeverityMajor
+	SeverityCritical
+)
+
+// Severities are all of the known severities from least to most severe.
+var Severities = []Severity{SeverityNone, SeverityMaintenance, SeverityMinor, SeverityMajor, SeverityCritical}
+
+var severityNames = map[Severity]string{
+	SeverityUnknown:     "unknown",
+	SeverityNone:        "none",
+	SeverityMaintenance: "maintenance",
+	SeverityMinor:       "minor",
+	SeverityMajor:       "major",
+	SeverityCritical:    "critical",
+}
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return severityNames[SeverityUnknown]
+}
+
+// MarshalText encodes the severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes the severity from its name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	if string(text) == severityNames[SeverityUnknown] {
+		*s = SeverityUnknown
+		return nil
+	}
+
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// AtLeast returns true if the severity is at least as severe as the given severity.
+func (s Severity) AtLeast(other Severity) bool {
+	return s >= other
+}
+
+// ParseSeverity parses a severity from its name. Indicators and component statuses are also accepted
+// and converted to their severity, so "partial_outage" is the same as "major".
+func ParseSeverity(severity string) (Severity, error) {
+	for _, s := range Severities {
+		if s.String() == severity {
+			return s, nil
+		}
+	}
+	if status, err := ParseComponentStatus(severity); err == nil {
+		return status.Severity(), nil
+	}
+	return SeverityUnknown, fmt.Errorf("unknown severity %q (valid values are [%s])", severity, joinValues(Severities))
+}
+
+// MaxSeverity returns the most severe of the given severities.
+func MaxSeverity(severities ...Severity) Severity {
+	max := SeverityUnknown
+	for _, s := range severities {
+		if s > max {
+			max = s
+		}
+	}
+	return max
+}
+
+// Indicators are all of the known indicators.
+var Indicators = []Indicator{None, Maintenance, Minor, Major, Critical}
+
+// Valid returns true if the indicator is known.
+func (i Indicator) Valid() bool {
+	return contains(Indicators, i)
+}
+
+// Severity returns the severity of the indicator.
+func (i Indicator) Severity() Severity {
+	switch i {
+	case None:
+		return SeverityNone
+	case Maintenance:
+		return SeverityMaintenance
+	case Minor:
+		return SeverityMinor
+	case Major:
+		return SeverityMajor
+	case Critical:
+		return SeverityCritical
+	default:
+		return SeverityUnknown
+	}
+}
+
+// ParseIndicator parses an indicator, returning an error if it's not known.
+func ParseIndicator(indicator string) (Indicator, error) {
+	return parse(indicator, Indicators, "indicator")
+}
+
+// ComponentStatuses are all of the known component statuses.
+var ComponentStatuses = []ComponentStatus{Operational, UnderMaintenance, DegradedPerformance, PartialOutage, MajorOutage}
+
+// Valid returns true if the component status is known.
+func (s ComponentStatus) Valid() bool {
+	return contains(ComponentStatuses, s)
+}
+
+// Severity returns the severity of the component status.
+func (s ComponentStatus) Severity() Severity {
+	switch s {
+	case Operational:
+		return SeverityNone
+	case UnderMaintenance:
+		return SeverityMaintenance
+	case DegradedPerformance:
+		return SeverityMinor
+	case PartialOutage:
+		return SeverityMajor
+	case MajorOutage:
+		return SeverityCritical
+	default:
+		return SeverityUnknown
+	}
+}
+
+// ParseComponentStatus parses a component status, returning an error if it's not known.
+func ParseComponentStatus(status string) (ComponentStatus, error) {
+	return parse(status, ComponentStatuses, "component status")
+}
+
+// IncidentStatuses are all of the known incident statuses.
+var IncidentStatuses = []IncidentStatus{Investigating, Identified, Monitoring, Resolved, Postmorten}
+
+// Valid returns true if the incident status is known.
+func (s IncidentStatus) Valid() bool {
+	return contains(IncidentStatuses, s)
+}
+
+// Resolved returns true if the incident is over.
+func (s IncidentStatus) Resolved() bool {
+	return s == Resolved || s == Postmorten
+}
+
+// Severity returns the severity of the incident status, which reflects how much attention it calls for.
+// An incident whose cause is still being investigated or fixed is major, one that is being monitored
+// is minor and one that is over has no severity.
+func (s IncidentStatus) Severity() Severity {
+	switch s {
+	case Investigating, Identified:
+		return SeverityMajor
+	case Monitoring:
+		return SeverityMinor
+	case Resolved, Postmorten:
+		return SeverityNone
+	default:
+		return SeverityUnknown
+	}
+}
+
+// ParseIncidentStatus parses an incident status, returning an error if it's not known.
+func ParseIncidentStatus(status string) (IncidentStatus, error) {
+	return parse(status, IncidentStatuses, "incident status")
+}
+
+// ScheduledMaintenanceStatuses are all of the known scheduled maintenance statuses.
+var ScheduledMaintenanceStatuses = []ScheduledMaintenanceStatus{Scheduled, InProgress, Verifying, Completed}
+
+// Valid returns true if the scheduled maintenance status is known.
+func (s ScheduledMaintenanceStatus) Valid() bool {
+	return contains(ScheduledMaintenanceStatuses, s)
+}
+
+// ParseScheduledMaintenanceStatus parses a scheduled maintenance status, returning an error if it's not known.
+func ParseScheduledMaintenanceStatus(status string) (ScheduledMaintenanceStatus, error) {
+	return parse(status, ScheduledMaintenanceStatuses, "scheduled maintenance status")
+}
+
+// Severity returns the severity of the incident. This is the severity of its impact until it's resolved.
+func (i Incident) Severity() Severity {
+	if i.Status.Resolved() {
+		return SeverityNone
+	}
+	return i.Impact.Severity()
+}
+
+// Severity returns the severity of the scheduled maintenance. This is maintenance while it's underway.
+func (s ScheduledMaintenance) Severity() Severity {
+	switch s.Status {
+	case InProgress, Verifying:
+		return SeverityMaintenance
+	default:
+		return SeverityNone
+	}
+}
+
+// Validate returns an error describing every unknown enum value in the summary. Statuspage may add
+// values that this package doesn't know about yet, so callers can decide whether this is fatal.
+func (s SummaryResponse) Validate() error {
+	var errs []error
+	if !s.Status.Indicator.Valid() {
+		errs = append(errs, fmt.Errorf("unknown status indicator %q", s.Status.Indicator))
+	}
+	for _, c := range s.Components {
+		if !c.Status.Valid() {
+			errs = append(errs, fmt.Errorf("component %s has unknown status %q", c.Name, c.Status))
+		}
+	}
+	for _, i := range s.Incidents {
+		if !i.Status.Valid() {
+			errs = append(errs, fmt.Errorf("incident %s has unknown status %q", i.Name, i.Status))
+		}
+		if !i.Impact.Valid() {
+			errs = append(errs, fmt.Errorf("incident %s has unknown impact %q", i.Name, i.Impact))
+		}
+	}
+	for _, m := range s.ScheduledMaintenances {
+		if !m.Status.Valid() {
+			errs = append(errs, fmt.Errorf("scheduled maintenance %s has unknown status %q", m.Name, m.Status))
+		}
+		if !m.Impact.Valid() {
+			errs = append(errs, fmt.Errorf("scheduled maintenance %s has unknown impact %q", m.Name, m.Impact))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parse returns the value matching the given string, or an error if there isn't one.
+func parse[T ~string](value string, values []T, kind string) (T, error) {
+	if contains(values, T(value)) {
+		return T(value), nil
+	}
+	return "", fmt.Errorf("unknown %s %q (valid values are [%s])", kind, value, joinValues(values))
+}
+
+// contains returns true if the value is in the list of values.
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// joinValues returns a comma separated list of the given values.
+func joinValues[T any](values []T) string {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, fmt.Sprint(v))
+	}
+	return strings.Join(strs, ", ")
+}
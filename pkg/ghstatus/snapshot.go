@@ -0,0 +1,112 @@
+package ghstatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Resource is a resource of a status page that can be fetched as part of a snapshot.
+type Resource string
+
+const (
+	ResourceSummary                       Resource = "summary"
+	ResourceStatus                        Resource = "status"
+	ResourceComponents                    Resource = "components"
+	ResourceUnresolvedIncidents           Resource = "unresolved-incidents"
+	ResourceAllIncidents                  Resource = "all-incidents"
+	ResourceUpcomingScheduledMaintenances Resource = "upcoming-scheduled-maintenances"
+	ResourceActiveScheduledMaintenances   Resource = "active-scheduled-maintenances"
+	ResourceAllScheduledMaintenances      Resource = "all-scheduled-maintenances"
+)
+
+// DefaultSnapshotResources are the resources fetched by Snapshot if none are given.
+var DefaultSnapshotResources = []Resource{
+	ResourceSummary,
+	ResourceUnresolvedIncidents,
+	ResourceUpcomingScheduledMaintenances,
+}
+
+// Snapshotter fetches several resources of a status page at once. Clients created by NewClient implement it.
+type Snapshotter interface {
+	// Snapshot fetches the given resources in parallel, or DefaultSnapshotResources if none are given.
+	Snapshot(ctx context.Context, resources ...Resource) (Snapshot, error)
+}
+
+// Snapshot is a number of resources of a status page fetched together. Resources that weren't
+// requested, or couldn't be fetched, are nil.
+type Snapshot struct {
+	Summary                       *SummaryResponse               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Status                        *StatusResponse                `json:"status,omitempty" yaml:"status,omitempty"`
+	Components                    *ComponentsResponse            `json:"components,omitempty" yaml:"components,omitempty"`
+	UnresolvedIncidents           *IncidentsResponse             `json:"unresolved_incidents,omitempty" yaml:"unresolved_incidents,omitempty"`
+	AllIncidents                  *IncidentsResponse             `json:"all_incidents,omitempty" yaml:"all_incidents,omitempty"`
+	UpcomingScheduledMaintenances *ScheduledMaintenancesResponse `json:"upcoming_scheduled_maintenances,omitempty" yaml:"upcoming_scheduled_maintenances,omitempty"`
+	ActiveScheduledMaintenances   *ScheduledMaintenancesResponse `json:"active_scheduled_maintenances,omitempty" yaml:"active_scheduled_maintenances,omitempty"`
+	AllScheduledMaintenances      *ScheduledMaintenancesResponse `json:"all_scheduled_maintenances,omitempty" yaml:"all_scheduled_maintenances,omitempty"`
+}
+
+// Snapshot fetches the given resources in parallel, or DefaultSnapshotResources if none are given.
+// If any of them can't be fetched, the errors are returned along with the resources that could be.
+func (c *client) Snapshot(ctx context.Context, resources ...Resource) (Snapshot, error) {
+	if len(resources) == 0 {
+		resources = DefaultSnapshotResources
+	}
+
+	var snapshot Snapshot
+	requested := map[Resource]bool{}
+	errs := make([]error, len(resources))
+	var wg sync.WaitGroup
+	for i, resource := range resources {
+		if requested[resource] {
+			continue
+		}
+		requested[resource] = true
+
+		wg.Add(1)
+		go func(i int, resource Resource) {
+			defer wg.Done()
+			if err := c.fetchResource(ctx, resource, &snapshot); err != nil {
+				errs[i] = fmt.Errorf("error fetching %s: %w", resource, err)
+			}
+		}(i, resource)
+	}
+	wg.Wait()
+
+	return snapshot, errors.Join(errs...)
+}
+
+// fetchResource fetches the resource into its field of the snapshot.
+func (c *client) fetchResource(ctx context.Context, resource Resource, snapshot *Snapshot) error {
+	switch resource {
+	case ResourceSummary:
+		return fetchInto(ctx, c.Summary, &snapshot.Summary)
+	case ResourceStatus:
+		return fetchInto(ctx, c.Status, &snapshot.Status)
+	case ResourceComponents:
+		return fetchInto(ctx, c.Components, &snapshot.Components)
+	case ResourceUnresolvedIncidents:
+		return fetchInto(ctx, c.UnresolvedIncidents, &snapshot.UnresolvedIncidents)
+	case ResourceAllIncidents:
+		return fetchInto(ctx, c.AllIncidents, &snapshot.AllIncidents)
+	case ResourceUpcomingScheduledMaintenances:
+		return fetchInto(ctx, c.UpcomingScheduledMaintenances, &snapshot.UpcomingScheduledMaintenances)
+	case ResourceActiveScheduledMaintenances:
+		return fetchInto(ctx, c.ActiveScheduledMaintenances, &snapshot.ActiveScheduledMaintenances)
+	case ResourceAllScheduledMaintenances:
+		return fetchInto(ctx, c.AllScheduledMaintenances, &snapshot.AllScheduledMaintenances)
+	default:
+		return fmt.Errorf("unknown resource %q", resource)
+	}
+}
+
+// fetchInto fetches a response and sets the field to it if it was fetched.
+func fetchInto[T any](ctx context.Context, fetch func(context.Context) (T, error), field **T) error {
+	resp, err := fetch(ctx)
+	if err != nil {
+		return err
+	}
+	*field = &resp
+	return nil
+}
@@ -0,0 +1,38 @@
+package ghstatus
+
+import (
+	"time"
+)
+
+// Staleness describes a response that couldn't be fetched, for which the last successfully fetched
+// response was returned instead.
+type Staleness struct {
+	// FetchedAt is when the response was last fetched successfully.
+	FetchedAt time.Time `json:"fetched_at" yaml:"fetched_at"`
+
+	// Age is how long ago the response was last fetched successfully, e.g. 5m30s.
+	Age string `json:"age" yaml:"age"`
+
+	// Error is the error fetching a fresh response.
+	Error string `json:"error" yaml:"error"`
+}
+
+// newStaleness returns the staleness of a response last fetched at the given time.
+func newStaleness(now time.Time, fetchedAt time.Time, err error) *Staleness {
+	return &Staleness{
+		FetchedAt: fetchedAt.UTC(),
+		Age:       now.Sub(fetchedAt).Round(time.Second).String(),
+		Error:     err.Error(),
+	}
+}
+
+// staler is implemented by responses that can be marked as stale.
+type staler interface {
+	setStale(*Staleness)
+}
+
+func (s *SummaryResponse) setStale(stale *Staleness)               { s.Stale = stale }
+func (s *StatusResponse) setStale(stale *Staleness)                { s.Stale = stale }
+func (c *ComponentsResponse) setStale(stale *Staleness)            { c.Stale = stale }
+func (i *IncidentsResponse) setStale(stale *Staleness)             { i.Stale = stale }
+func (s *ScheduledMaintenancesResponse) setStale(stale *Staleness) { s.Stale = stale }
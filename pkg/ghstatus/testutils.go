@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,6 +14,9 @@ import (
 // TestServer is a test server with fixed responses that can be modified by
 // callers.
 type TestServer struct {
+	// URL is the base URL of the test server.
+	URL string
+
 	Summary                       []byte
 	Status                        []byte
 	Components                    []byte
@@ -21,33 +25,60 @@ type TestServer struct {
 	UpcomingScheduledMaintenances []byte
 	ActiveScheduledMaintenances   []byte
 	AllScheduledMaintenances      []byte
+
+	mu sync.Mutex
 }
 
 // SetSummary will set the summary response by encoding it into bytes.
 func (ts *TestServer) SetSummary(t *testing.T, resp SummaryResponse) {
-	ts.Summary = jsonEncode(t, resp)
+	ts.SetSummaryRaw(jsonEncode(t, resp))
 }
 
 // SetSummaryRaw will set the summary response to be the given raw bytes.
-func (ts *TestServer) SetSummaryRaw(resp []byte) { ts.Summary = resp }
+func (ts *TestServer) SetSummaryRaw(resp []byte) { ts.set(&ts.Summary, resp) }
+
+// SetAllIncidents will set the all incidents response by encoding it into bytes.
+func (ts *TestServer) SetAllIncidents(t *testing.T, resp IncidentsResponse) {
+	ts.set(&ts.AllIncidents, jsonEncode(t, resp))
+}
+
+// SetAllScheduledMaintenances will set the all scheduled maintenances response by encoding it into bytes.
+func (ts *TestServer) SetAllScheduledMaintenances(t *testing.T, resp ScheduledMaintenancesResponse) {
+	ts.set(&ts.AllScheduledMaintenances, jsonEncode(t, resp))
+}
 
-func (ts *TestServer) summary() []byte             { return ts.Summary }
-func (ts *TestServer) status() []byte              { return ts.Status }
-func (ts *TestServer) components() []byte          { return ts.Components }
-func (ts *TestServer) unresolvedIncidents() []byte { return ts.UnresolvedIncidents }
-func (ts *TestServer) allIncidents() []byte        { return ts.AllIncidents }
+func (ts *TestServer) summary() []byte             { return ts.get(&ts.Summary) }
+func (ts *TestServer) status() []byte              { return ts.get(&ts.Status) }
+func (ts *TestServer) components() []byte          { return ts.get(&ts.Components) }
+func (ts *TestServer) unresolvedIncidents() []byte { return ts.get(&ts.UnresolvedIncidents) }
+func (ts *TestServer) allIncidents() []byte        { return ts.get(&ts.AllIncidents) }
 func (ts *TestServer) upcomingScheduledMaintenances() []byte {
-	return ts.UpcomingScheduledMaintenances
+	return ts.get(&ts.UpcomingScheduledMaintenances)
 }
 func (ts *TestServer) activeScheduledMaintenances() []byte {
-	return ts.ActiveScheduledMaintenances
+	return ts.get(&ts.ActiveScheduledMaintenances)
 }
 func (ts *TestServer) allScheduledMaintenances() []byte {
-	return ts.AllScheduledMaintenances
+	return ts.get(&ts.AllScheduledMaintenances)
+}
+
+// set will set the given response while holding the lock.
+func (ts *TestServer) set(field *[]byte, resp []byte) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	*field = resp
+}
+
+// get will get the given response while holding the lock.
+func (ts *TestServer) get(field *[]byte) []byte {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return *field
 }
 
-// NewTestServerAndClient creates a new test server with the client pointing to it.
-func NewTestServerAndClient(t *testing.T) (*TestServer, Client) {
+// NewTestServerAndClient creates a new test server with the client pointing to it. Any
+// given options are applied to the client after pointing it at the test server.
+func NewTestServerAndClient(t *testing.T, opts ...ClientOption) (*TestServer, Client) {
 	testServer := &TestServer{}
 
 	mux := http.NewServeMux()
@@ -62,8 +93,9 @@ func NewTestServerAndClient(t *testing.T) (*TestServer, Client) {
 
 	server := httptest.NewServer(mux)
 	t.Cleanup(server.Close)
+	testServer.URL = server.URL
 
-	return testServer, newClient(zap.NewNop(), server.URL)
+	return testServer, newClient(zap.NewNop(), append([]ClientOption{WithBaseURL(server.URL)}, opts...)...)
 }
 
 func response(t *testing.T, response func() []byte) http.HandlerFunc {
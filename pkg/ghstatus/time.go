@@ -0,0 +1,88 @@
+package ghstatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// noTime is how a null time is displayed.
+	noTime = "—"
+)
+
+// NullTime is a timestamp that may be null in the API, such as when an incident is yet to be resolved.
+// Unlike a plain time.Time, it distinguishes a null or missing value from a real one.
+type NullTime struct {
+	// Time is the timestamp. This is only meaningful if Valid is true.
+	Time time.Time
+
+	// Valid is whether the timestamp is set.
+	Valid bool
+}
+
+// NewNullTime returns a set NullTime for the given time.
+func NewNullTime(t time.Time) NullTime {
+	return NullTime{Time: t, Valid: true}
+}
+
+// String returns the timestamp, or "—" if it's not set.
+func (t NullTime) String() string {
+	if !t.Valid {
+		return noTime
+	}
+	return t.Time.String()
+}
+
+// MarshalJSON encodes the timestamp, or null if it's not set.
+func (t NullTime) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time)
+}
+
+// UnmarshalJSON decodes the timestamp. Null and zero timestamps are treated as not set, the latter
+// so that values encoded with plain timestamps can still be decoded.
+func (t *NullTime) UnmarshalJSON(data []byte) error {
+	*t = NullTime{}
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var parsed time.Time
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	if !parsed.IsZero() {
+		*t = NewNullTime(parsed)
+	}
+	return nil
+}
+
+// MarshalYAML encodes the timestamp, or null if it's not set.
+func (t NullTime) MarshalYAML() (any, error) {
+	if !t.Valid {
+		return nil, nil
+	}
+	return t.Time, nil
+}
+
+// UnmarshalYAML decodes the timestamp. Null and zero timestamps are treated as not set.
+func (t *NullTime) UnmarshalYAML(value *yaml.Node) error {
+	*t = NullTime{}
+	if value.Tag == "!!null" {
+		return nil
+	}
+
+	var parsed time.Time
+	if err := value.Decode(&parsed); err != nil {
+		return err
+	}
+	if !parsed.IsZero() {
+		*t = NewNullTime(parsed)
+	}
+	return nil
+}
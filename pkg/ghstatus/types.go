@@ -19,14 +19,16 @@ type Page struct {
 	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
 }
 
-// Indicator is an indicator of the status of an incident or the overall status.
+// Indicator is an indicator of the status of an incident or the overall status. See Severity for
+// comparing indicators.
 type Indicator string
 
 const (
-	None     Indicator = "none"
-	Minor    Indicator = "minor"
-	Major    Indicator = "major"
-	Critical Indicator = "critical"
+	None        Indicator = "none"
+	Maintenance Indicator = "maintenance"
+	Minor       Indicator = "minor"
+	Major       Indicator = "major"
+	Critical    Indicator = "critical"
 )
 
 // Status is an overall description of the current github status.
@@ -38,30 +40,38 @@ type Status struct {
 	Indicator Indicator `json:"indicator" yaml:"indicator"`
 }
 
-// ComponentStatus is the current status of a github component.
+// ComponentStatus is the current status of a github component. See Severity for comparing statuses.
 type ComponentStatus string
 
 const (
 	Operational         ComponentStatus = "operational"
+	UnderMaintenance    ComponentStatus = "under_maintenance"
 	DegradedPerformance ComponentStatus = "degraded_performance"
 	PartialOutage       ComponentStatus = "partial_outage"
 	MajorOutage         ComponentStatus = "major_outage"
 )
 
-// Component is a github component along with its current status.
+// Component is a github component along with its current status. Components can be
+// groups of other components, see ComponentTree for walking the hierarchy.
 type Component struct {
+	// Components are the IDs of the components in this group. This is only populated for groups.
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+
 	// CreatedAt is when the component was created.
 	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 
 	// Description is the description of the component.
 	Description string `json:"description" yaml:"description"`
 
-	// Group is is whether this component is in a group.
+	// Group is whether this component is a group of other components.
 	Group bool `json:"group" yaml:"group"`
 
 	// GroupID is the ID of the group this component belongs to.
 	GroupID string `json:"group_id" yaml:"group_id"`
 
+	// ID is the identifier of the component.
+	ID string `json:"id" yaml:"id"`
+
 	// Name is the name of the component.
 	Name string `json:"name" yaml:"name"`
 
@@ -100,6 +110,9 @@ const (
 
 // Incident is an ongoing incident.
 type Incident struct {
+	// Components are the components affected by the incident.
+	Components []Component `json:"components,omitempty" yaml:"components,omitempty"`
+
 	// CreatedAt is when the incident was created.
 	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 
@@ -112,8 +125,8 @@ type Incident struct {
 	// IncidentUpdates are the updates for an incident.
 	IncidentUpdates []IncidentUpdate `json:"incident_updates" yaml:"incident_updates"`
 
-	// MonitoringAt is the time when the incident is being monitored.
-	MonitoringAt time.Time `json:"monitoring_at" yaml:"monitoring_at"`
+	// MonitoringAt is the time when the incident started being monitored, if it has been.
+	MonitoringAt NullTime `json:"monitoring_at" yaml:"monitoring_at"`
 
 	// Name is the name of the incident.
 	Name string `json:"name" yaml:"name"`
@@ -121,8 +134,8 @@ type Incident struct {
 	// PageID is the ID of this page.
 	PageID string `json:"page_id" yaml:"page_id"`
 
-	// ResolvedAt is when the incident was resolved.
-	ResolvedAt time.Time `json:"resolved_at" yaml:"resolved_at"`
+	// ResolvedAt is when the incident was resolved, if it has been.
+	ResolvedAt NullTime `json:"resolved_at" yaml:"resolved_at"`
 
 	// ShortLink is the shortlink to this incident.
 	Shortlink string `json:"shortlink" yaml:"shortlink"`
@@ -134,8 +147,26 @@ type Incident struct {
 	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
 }
 
+// AffectedComponent is a component affected by an incident update, along with how its status changed.
+type AffectedComponent struct {
+	// Code is the ID of the affected component.
+	Code string `json:"code" yaml:"code"`
+
+	// Name is the name of the affected component.
+	Name string `json:"name" yaml:"name"`
+
+	// OldStatus is the status of the component before the update.
+	OldStatus ComponentStatus `json:"old_status" yaml:"old_status"`
+
+	// NewStatus is the status of the component after the update.
+	NewStatus ComponentStatus `json:"new_status" yaml:"new_status"`
+}
+
 // IncidentUpdate is an update to an incident.
 type IncidentUpdate struct {
+	// AffectedComponents are the components affected by this update.
+	AffectedComponents []AffectedComponent `json:"affected_components,omitempty" yaml:"affected_components,omitempty"`
+
 	// Body is the plaintext description of the update.
 	Body string `json:"body" yaml:"body"`
 
@@ -170,6 +201,9 @@ const (
 
 // ScheduledMaintenance is a scheduled maintenance.
 type ScheduledMaintenance struct {
+	// Components are the components affected by the scheduled maintenance.
+	Components []Component `json:"components,omitempty" yaml:"components,omitempty"`
+
 	// CreatedAt is when the scheduled maintenance was created.
 	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 
@@ -182,8 +216,8 @@ type ScheduledMaintenance struct {
 	// IncidentUpdates are updates to the scheduled maintenance.
 	IncidentUpdates []IncidentUpdate `json:"incident_updates" yaml:"incident_updates"`
 
-	// MonitoringAt is the time when the scheduled maintenance is being monitored.
-	MonitoringAt time.Time `json:"monitoring_at" yaml:"monitoring_at"`
+	// MonitoringAt is the time when the scheduled maintenance started being monitored, if it has been.
+	MonitoringAt NullTime `json:"monitoring_at" yaml:"monitoring_at"`
 
 	// Name is the name of the scheduled maintenance.
 	Name string `json:"name" yaml:"name"`
@@ -191,14 +225,14 @@ type ScheduledMaintenance struct {
 	// PageID is the ID of this page.
 	PageID string `json:"page_id" yaml:"page_id"`
 
-	// ResolvedAt is the time when the scheduled maintenance is resolved.
-	ResolvedAt time.Time `json:"resolved_at" yaml:"resolved_at"`
+	// ResolvedAt is the time when the scheduled maintenance was resolved, if it has been.
+	ResolvedAt NullTime `json:"resolved_at" yaml:"resolved_at"`
 
 	// ScheduledFor is when the scheduled maintenance is scheduled.
-	ScheduledFor time.Time `json:"scheduled_for" yaml:"scheduled_for"`
+	ScheduledFor NullTime `json:"scheduled_for" yaml:"scheduled_for"`
 
 	// ScheduledUntil is when the scheduled maintenance is supposed to end.
-	ScheduledUntil time.Time `json:"scheduled_until" yaml:"scheduled_until"`
+	ScheduledUntil NullTime `json:"scheduled_until" yaml:"scheduled_until"`
 
 	// Status is the status of the scheduled maintenance.
 	Status ScheduledMaintenanceStatus `json:"status" yaml:"status"`
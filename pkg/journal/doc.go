@@ -0,0 +1,8 @@
+// Package journal contains an append-only journal of detected status page changes.
+//
+// The monitor writes every change it detects to the journal as a JSON line holding
+// the time the change was detected along with the before and after values of the
+// changed resource. The journal can later be queried by time range, page, component,
+// incident or kind, which allows answering questions about past outages without
+// relying on the limited history provided by the status API.
+package journal
@@ -0,0 +1,378 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// Kind is the kind of resource a journal entry is about.
+type Kind = notifier.Kind
+
+const (
+	KindStatus               = notifier.KindStatus
+	KindComponent            = notifier.KindComponent
+	KindIncident             = notifier.KindIncident
+	KindScheduledMaintenance = notifier.KindScheduledMaintenance
+	KindSource               = notifier.KindSource
+)
+
+// Kinds are all of the supported kinds.
+var Kinds = notifier.Kinds
+
+// KindFromString returns a Kind from a string descriptor.
+func KindFromString(kind string) (Kind, error) {
+	return notifier.KindFromString(kind)
+}
+
+// summaryFields are the fields that are compared when summarizing an entry.
+var summaryFields = []string{"name", "status", "indicator", "impact", "description"}
+
+// Entry is a single change recorded in the journal.
+type Entry struct {
+	// Time is when the change was detected.
+	Time time.Time `json:"time" yaml:"time"`
+
+	// Page is the name of the status page the change was detected on.
+	Page string `json:"page" yaml:"page"`
+
+	// Kind is the kind of resource that changed.
+	Kind Kind `json:"kind" yaml:"kind"`
+
+	// Type is the type of change.
+	Type notifier.ChangeType `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// ID is the identifier of the resource that changed. This is empty for the overall status.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// Name is the name of the resource that changed.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Group is the name of the group the component belongs to. This is only set for components in a group.
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+
+	// Components are the names of the components affected by an incident or scheduled maintenance.
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+
+	// Fields are the fields that changed along with their previous and current values.
+	Fields []notifier.FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// Before is the resource before the change. This is empty if the resource is new.
+	Before json.RawMessage `json:"before,omitempty" yaml:"-"`
+
+	// After is the resource after the change. This is empty if the resource was removed.
+	After json.RawMessage `json:"after,omitempty" yaml:"-"`
+}
+
+// NewEntry creates a new entry for the given change, encoding the before and after values.
+// A nil before value designates a new resource and a nil after value designates a removed one.
+func NewEntry(t time.Time, page string, change notifier.Change, before any, after any) (Entry, error) {
+	entry := Entry{
+		Time:       t,
+		Page:       page,
+		Kind:       change.Kind,
+		Type:       change.Type,
+		ID:         change.ID,
+		Name:       change.Name,
+		Group:      change.Group,
+		Components: change.Components,
+		Fields:     change.Fields,
+	}
+
+	var err error
+	if before != nil {
+		entry.Before, err = json.Marshal(before)
+		if err != nil {
+			return Entry{}, fmt.Errorf("error encoding before value: %w", err)
+		}
+	}
+
+	if after != nil {
+		entry.After, err = json.Marshal(after)
+		if err != nil {
+			return Entry{}, fmt.Errorf("error encoding after value: %w", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// MarshalYAML renders the before and after values as YAML rather than raw bytes.
+func (e Entry) MarshalYAML() (any, error) {
+	type yamlEntry struct {
+		Time       time.Time              `yaml:"time"`
+		Page       string                 `yaml:"page"`
+		Kind       Kind                   `yaml:"kind"`
+		Type       notifier.ChangeType    `yaml:"type,omitempty"`
+		ID         string                 `yaml:"id,omitempty"`
+		Name       string                 `yaml:"name,omitempty"`
+		Group      string                 `yaml:"group,omitempty"`
+		Components []string               `yaml:"components,omitempty"`
+		Fields     []notifier.FieldChange `yaml:"fields,omitempty"`
+		Before     any                    `yaml:"before,omitempty"`
+		After      any                    `yaml:"after,omitempty"`
+	}
+
+	entry := yamlEntry{
+		Time:       e.Time,
+		Page:       e.Page,
+		Kind:       e.Kind,
+		Type:       e.Type,
+		ID:         e.ID,
+		Name:       e.Name,
+		Group:      e.Group,
+		Components: e.Components,
+		Fields:     e.Fields,
+	}
+	if len(e.Before) > 0 {
+		if err := json.Unmarshal(e.Before, &entry.Before); err != nil {
+			return nil, fmt.Errorf("error decoding before value: %w", err)
+		}
+	}
+	if len(e.After) > 0 {
+		if err := json.Unmarshal(e.After, &entry.After); err != nil {
+			return nil, fmt.Errorf("error decoding after value: %w", err)
+		}
+	}
+
+	return entry, nil
+}
+
+// Summary returns a short human readable description of what changed, e.g.
+// "status: operational → major_outage". Entries without field changes fall back to
+// comparing the before and after values.
+func (e Entry) Summary() string {
+	if e.Type == notifier.Removed {
+		return "no longer listed"
+	}
+	if len(e.Fields) > 0 {
+		return summarizeFields(len(e.Before) == 0, e.Fields)
+	}
+
+	before := map[string]any{}
+	after := map[string]any{}
+	if len(e.Before) > 0 {
+		_ = json.Unmarshal(e.Before, &before)
+	}
+	if len(e.After) > 0 {
+		_ = json.Unmarshal(e.After, &after)
+	}
+
+	var changes []string
+	for _, field := range summaryFields {
+		beforeValue, beforeOK := before[field]
+		afterValue, afterOK := after[field]
+		if !afterOK || afterValue == nil || afterValue == "" {
+			continue
+		}
+		switch {
+		case len(e.Before) == 0:
+			if field == "name" {
+				continue
+			}
+			changes = append(changes, fmt.Sprintf("%s: %v", field, afterValue))
+		case !beforeOK || fmt.Sprint(beforeValue) != fmt.Sprint(afterValue):
+			changes = append(changes, fmt.Sprintf("%s: %s", field, notifier.Transition(fmt.Sprint(beforeValue), fmt.Sprint(afterValue))))
+		}
+	}
+
+	if len(e.Before) == 0 {
+		return strings.TrimSpace("new " + strings.Join(changes, ", "))
+	}
+	if len(changes) == 0 {
+		return "updated"
+	}
+	return strings.Join(changes, ", ")
+}
+
+// summarizeFields returns a short human readable description of the changed fields.
+func summarizeFields(added bool, fields []notifier.FieldChange) string {
+	var changes []string
+	for _, field := range fields {
+		switch {
+		case added && field.Field == notifier.FieldName:
+		case added:
+			changes = append(changes, fmt.Sprintf("%s: %s", field.Field, field.Current))
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %s", field.Field, notifier.Transition(field.Previous, field.Current)))
+		}
+	}
+
+	if added {
+		return strings.TrimSpace("new " + strings.Join(changes, ", "))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// Journal is an append-only journal stored as a file of JSON lines.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the journal at the given path for appending, creating it if necessary.
+func Open(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal: %w", err)
+	}
+
+	return &Journal{
+		file: file,
+	}, nil
+}
+
+// Append appends the given entries to the journal.
+func (j *Journal) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("error encoding journal entry: %w", err)
+		}
+		buf = append(buf, data...)
+		buf = append(buf, '\n')
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Write all of the entries at once so that concurrent writers don't interleave lines.
+	if _, err := j.file.Write(buf); err != nil {
+		return fmt.Errorf("error writing journal entries: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the journal.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// Query describes which entries to return when reading the journal. Empty fields match everything.
+type Query struct {
+	// Since only matches entries at or after this time.
+	Since time.Time
+
+	// Until only matches entries before this time.
+	Until time.Time
+
+	// Page only matches entries for the page with this name.
+	Page string
+
+	// Component only matches component entries with this name or ID, and incident and scheduled
+	// maintenance entries affecting a component with this name.
+	Component string
+
+	// Incident only matches incident entries with this name or ID.
+	Incident string
+
+	// Kinds only matches entries of these kinds.
+	Kinds []Kind
+}
+
+// Matches returns true if the entry matches the query.
+func (q Query) Matches(entry Entry) bool {
+	if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !entry.Time.Before(q.Until) {
+		return false
+	}
+	if q.Page != "" && !strings.EqualFold(q.Page, entry.Page) {
+		return false
+	}
+	if q.Component != "" && !matchesComponent(q.Component, entry) {
+		return false
+	}
+	if q.Incident != "" && (entry.Kind != KindIncident || !matchesResource(q.Incident, entry)) {
+		return false
+	}
+	if len(q.Kinds) > 0 {
+		found := false
+		for _, kind := range q.Kinds {
+			if kind == entry.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesComponent returns true if the entry is about the given component or affects it.
+func matchesComponent(component string, entry Entry) bool {
+	if entry.Kind == KindComponent {
+		return matchesResource(component, entry)
+	}
+	for _, name := range entry.Components {
+		if strings.EqualFold(component, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesResource returns true if the name or ID of the entry matches the given value.
+func matchesResource(value string, entry Entry) bool {
+	return value == entry.ID || strings.EqualFold(value, entry.Name)
+}
+
+// Read reads every entry matching the query from the journal at the given path, ordered by time.
+// A journal that doesn't exist yet has no entries.
+func Read(path string, query Query) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error decoding journal line %d: %w", line, err)
+		}
+
+		if query.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading journal: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,110 @@
+package journal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	entries, err := Read(path, Query{})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	now := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+	before := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.Operational}
+	after := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.MajorOutage}
+	component, err := NewEntry(now, "GitHub", notifier.Change{
+		Kind:   KindComponent,
+		Type:   notifier.Updated,
+		ID:     "actions",
+		Name:   "Actions",
+		Group:  "CI/CD",
+		Fields: []notifier.FieldChange{{Field: notifier.FieldStatus, Previous: "operational", Current: "major_outage"}},
+	}, before, after)
+	require.NoError(t, err)
+	incident, err := NewEntry(now.Add(-time.Minute), "GitHub", notifier.Change{
+		Kind:       KindIncident,
+		Type:       notifier.Added,
+		ID:         "incident",
+		Name:       "Actions outage",
+		Components: []string{"Actions"},
+	}, nil, ghstatus.Incident{ID: "incident", Name: "Actions outage"})
+	require.NoError(t, err)
+
+	journal, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, journal.Append(component))
+	require.NoError(t, journal.Append(incident))
+	require.NoError(t, journal.Close())
+
+	// Entries are ordered by time regardless of the order they were appended in.
+	entries, err = Read(path, Query{})
+	require.NoError(t, err)
+	require.Equal(t, []Entry{incident, component}, entries)
+	require.Equal(t, "CI/CD", entries[1].Group)
+	require.Empty(t, entries[0].Before)
+
+	entries, err = Read(path, Query{Kinds: []Kind{KindComponent}})
+	require.NoError(t, err)
+	require.Equal(t, []Entry{component}, entries)
+}
+
+func TestQueryMatches(t *testing.T) {
+	now := time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+	component := Entry{Time: now, Page: "GitHub", Kind: KindComponent, ID: "actions", Name: "Actions"}
+	incident := Entry{Time: now, Page: "GitHub", Kind: KindIncident, ID: "incident", Name: "Outage", Components: []string{"Actions", "Pages"}}
+	status := Entry{Time: now, Page: "npm", Kind: KindStatus}
+
+	for _, test := range []struct {
+		name    string
+		query   Query
+		matches []Entry
+	}{
+		{name: "empty", query: Query{}, matches: []Entry{component, incident, status}},
+		{name: "since", query: Query{Since: now}, matches: []Entry{component, incident, status}},
+		{name: "until", query: Query{Until: now}, matches: nil},
+		{name: "page", query: Query{Page: "github"}, matches: []Entry{component, incident}},
+		{name: "component by ID", query: Query{Component: "actions"}, matches: []Entry{component, incident}},
+		{name: "affected component", query: Query{Component: "pages"}, matches: []Entry{incident}},
+		{name: "incident", query: Query{Incident: "outage"}, matches: []Entry{incident}},
+		{name: "kinds", query: Query{Kinds: []Kind{KindStatus, KindComponent}}, matches: []Entry{component, status}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var matches []Entry
+			for _, entry := range []Entry{component, incident, status} {
+				if test.query.Matches(entry) {
+					matches = append(matches, entry)
+				}
+			}
+			require.Equal(t, test.matches, matches)
+		})
+	}
+}
+
+func TestEntrySummary(t *testing.T) {
+	require.Equal(t, "no longer listed", Entry{Type: notifier.Removed}.Summary())
+	require.Equal(t, "status: operational → major_outage", Entry{
+		Before: []byte(`{}`),
+		Fields: []notifier.FieldChange{{Field: notifier.FieldStatus, Previous: "operational", Current: "major_outage"}},
+	}.Summary())
+	require.Equal(t, "new status: investigating", Entry{
+		Fields: []notifier.FieldChange{
+			{Field: notifier.FieldName, Current: "Outage"},
+			{Field: notifier.FieldStatus, Current: "investigating"},
+		},
+	}.Summary())
+
+	// Entries without field changes compare the before and after values.
+	require.Equal(t, "status: operational → major_outage", Entry{
+		Before: []byte(`{"name": "Actions", "status": "operational"}`),
+		After:  []byte(`{"name": "Actions", "status": "major_outage"}`),
+	}.Summary())
+	require.Equal(t, "updated", Entry{Before: []byte(`{"name": "Actions"}`), After: []byte(`{"name": "Actions"}`)}.Summary())
+}
@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/journal"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"go.uber.org/zap"
+)
+
+// pollFailed records a failed poll of the page and reports the page as unavailable once the number of
+// failures in a row reaches the threshold.
+func (m *Monitor) pollFailed(ctx context.Context, p *page, err error) error {
+	if m.unavailableAfter <= 0 {
+		return nil
+	}
+	var rateLimitErr *ghstatus.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		// The page is up, it's just asking to be polled less often.
+		return nil
+	}
+
+	if p.failures == 0 {
+		p.failingSince = m.clock.Now()
+	}
+	p.failures++
+	if p.failures < m.unavailableAfter || !p.unavailableSince.IsZero() {
+		return nil
+	}
+
+	p.log.With(zap.Int("failures", p.failures)).Warn("Status page is unavailable.")
+	p.unavailableSince = p.failingSince
+	errs := []error{m.notifyAvailability(ctx, p, notifier.Change{
+		Kind: notifier.KindSource,
+		Type: notifier.Unavailable,
+		Name: p.name,
+		Fields: []notifier.FieldChange{
+			{Field: notifier.FieldFailures, Current: strconv.Itoa(p.failures)},
+			{Field: notifier.FieldError, Current: err.Error()},
+		},
+	})}
+	errs = append(errs, p.save(ctx, m.stateStore))
+	return errors.Join(errs...)
+}
+
+// pollSucceeded records a successful poll of the page and reports that the page has recovered if it had
+// been reported as unavailable.
+func (m *Monitor) pollSucceeded(ctx context.Context, p *page) error {
+	p.failures = 0
+	if p.unavailableSince.IsZero() {
+		return nil
+	}
+
+	unavailableFor := m.clock.Since(p.unavailableSince).Round(time.Second)
+	p.log.With(zap.Duration("unavailable_for", unavailableFor)).Info("Status page has recovered.")
+	p.unavailableSince = time.Time{}
+	errs := []error{m.notifyAvailability(ctx, p, notifier.Change{
+		Kind: notifier.KindSource,
+		Type: notifier.Recovered,
+		Name: p.name,
+		Fields: []notifier.FieldChange{
+			{Field: notifier.FieldUnavailableFor, Current: formatDuration(unavailableFor)},
+		},
+	})}
+	errs = append(errs, p.save(ctx, m.stateStore))
+	return errors.Join(errs...)
+}
+
+// notifyAvailability records the change to the availability of the page in the journal and sends it to
+// the notifiers.
+func (m *Monitor) notifyAvailability(ctx context.Context, p *page, change notifier.Change) error {
+	var errs []error
+	if m.journal != nil {
+		entry := journal.Entry{
+			Time:   m.clock.Now().UTC(),
+			Page:   p.name,
+			Kind:   change.Kind,
+			Type:   change.Type,
+			Name:   change.Name,
+			Fields: change.Fields,
+		}
+		if err := m.journal.Append(entry); err != nil {
+			errs = append(errs, fmt.Errorf("error writing to journal: %w", err))
+		}
+	}
+
+	msg := notifier.Message{Page: p.name, Changes: []notifier.Change{change}}
+	if m.filter != nil {
+		msg = msg.Filter(m.filter.Matches)
+	}
+	if !msg.Empty() {
+		errs = append(errs, m.notify(ctx, p, msg))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// failingClient fails to get the summary while err is set.
+type failingClient struct {
+	ghstatus.Client
+	err error
+}
+
+func (c *failingClient) Summary(ctx context.Context) (ghstatus.SummaryResponse, error) {
+	if c.err != nil {
+		return ghstatus.SummaryResponse{}, c.err
+	}
+	return c.Client.Summary(ctx)
+}
+
+func TestMonitorReportsUnavailablePages(t *testing.T) {
+	ctx := context.Background()
+	clock := clockwork.NewFakeClock()
+	server, testClient := ghstatus.NewTestServerAndClient(t)
+	client := &failingClient{Client: testClient}
+
+	m := New(zap.NewNop(), clock, false, WithUnavailableAfter(3))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+
+	summary := ghstatus.SummaryResponse{Page: ghstatus.Page{UpdatedAt: clock.Now().UTC()}}
+	server.SetSummary(t, summary)
+	p := newPage(m.log, client)
+	require.NoError(t, m.detectChangesAndNotify(ctx, p))
+
+	// Nothing is reported until the threshold is reached.
+	client.err = errors.New("bad gateway")
+	for i := 0; i < 2; i++ {
+		clock.Advance(time.Minute)
+		require.ErrorContains(t, m.detectChangesAndNotify(ctx, p), "bad gateway")
+	}
+	require.Empty(t, ch)
+
+	clock.Advance(time.Minute)
+	require.ErrorContains(t, m.detectChangesAndNotify(ctx, p), "bad gateway")
+	require.Equal(t, notifier.Message{
+		Page: ghstatus.PageName(client),
+		Changes: []notifier.Change{
+			{
+				Kind: notifier.KindSource,
+				Type: notifier.Unavailable,
+				Name: ghstatus.PageName(client),
+				Fields: []notifier.FieldChange{
+					{Field: notifier.FieldFailures, Current: "3"},
+					{Field: notifier.FieldError, Current: "bad gateway"},
+				},
+			},
+		},
+	}, <-ch)
+
+	// The page is only reported as unavailable once.
+	clock.Advance(time.Minute)
+	require.Error(t, m.detectChangesAndNotify(ctx, p))
+	require.Empty(t, ch)
+
+	client.err = nil
+	clock.Advance(time.Minute)
+	require.NoError(t, m.detectChangesAndNotify(ctx, p))
+	require.Equal(t, notifier.Message{
+		Page: ghstatus.PageName(client),
+		Changes: []notifier.Change{
+			{
+				Kind: notifier.KindSource,
+				Type: notifier.Recovered,
+				Name: ghstatus.PageName(client),
+				Fields: []notifier.FieldChange{
+					{Field: notifier.FieldUnavailableFor, Current: "4m"},
+				},
+			},
+		},
+	}, <-ch)
+
+	clock.Advance(time.Minute)
+	require.NoError(t, m.detectChangesAndNotify(ctx, p))
+	require.Empty(t, ch)
+}
+
+func TestMonitorIgnoresRateLimitingForAvailability(t *testing.T) {
+	ctx := context.Background()
+	clock := clockwork.NewFakeClock()
+	_, testClient := ghstatus.NewTestServerAndClient(t)
+	client := &failingClient{Client: testClient, err: &ghstatus.RateLimitError{RetryAfter: time.Minute}}
+
+	m := New(zap.NewNop(), clock, false, WithUnavailableAfter(1))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+
+	p := newPage(m.log, client)
+	for i := 0; i < 3; i++ {
+		err := m.detectChangesAndNotify(ctx, p)
+		var rateLimitErr *ghstatus.RateLimitError
+		require.ErrorAs(t, err, &rateLimitErr)
+	}
+	require.Empty(t, ch)
+}
@@ -0,0 +1,481 @@
+package monitor
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// these functions will be used for finding changed resources generically.
+func getComponentID(component ghstatus.Component) string           { return component.ID }
+func getComponentName(component ghstatus.Component) string         { return component.Name }
+func getComponentUpdatedAt(component ghstatus.Component) time.Time { return component.UpdatedAt }
+func getIncidentID(incident ghstatus.Incident) string              { return incident.ID }
+func getIncidentName(incident ghstatus.Incident) string            { return incident.Name }
+func getIncidentUpdatedAt(incident ghstatus.Incident) time.Time    { return incident.UpdatedAt }
+func getScheduledMaintenanceID(scheduledMaintenance ghstatus.ScheduledMaintenance) string {
+	return scheduledMaintenance.ID
+}
+func getScheduledMaintenanceName(scheduledMaintenance ghstatus.ScheduledMaintenance) string {
+	return scheduledMaintenance.Name
+}
+func getScheduledMaintenanceUpdatedAt(scheduledMaintenance ghstatus.ScheduledMaintenance) time.Time {
+	return scheduledMaintenance.UpdatedAt
+}
+
+func getStatusSeverity(status ghstatus.Status) ghstatus.Severity { return status.Indicator.Severity() }
+func getComponentSeverity(component ghstatus.Component) ghstatus.Severity {
+	return component.Status.Severity()
+}
+func getIncidentSeverity(incident ghstatus.Incident) ghstatus.Severity { return incident.Severity() }
+func getScheduledMaintenanceSeverity(scheduledMaintenance ghstatus.ScheduledMaintenance) ghstatus.Severity {
+	return scheduledMaintenance.Severity()
+}
+
+func getIncidentFinished(incident ghstatus.Incident) bool { return incident.Status.Resolved() }
+func getScheduledMaintenanceFinished(scheduledMaintenance ghstatus.ScheduledMaintenance) bool {
+	return scheduledMaintenance.Status == ghstatus.Completed
+}
+
+func getStatusFields(status ghstatus.Status) []field {
+	return []field{
+		{notifier.FieldIndicator, string(status.Indicator)},
+		{notifier.FieldDescription, status.Description},
+	}
+}
+func getComponentFields(component ghstatus.Component) []field {
+	return []field{
+		{notifier.FieldName, component.Name},
+		{notifier.FieldStatus, string(component.Status)},
+		{notifier.FieldDescription, component.Description},
+	}
+}
+func getIncidentFields(incident ghstatus.Incident) []field {
+	return []field{
+		{notifier.FieldName, incident.Name},
+		{notifier.FieldStatus, string(incident.Status)},
+		{notifier.FieldImpact, string(incident.Impact)},
+		{notifier.FieldComponents, formatComponents(incident.Components)},
+	}
+}
+func getScheduledMaintenanceFields(scheduledMaintenance ghstatus.ScheduledMaintenance) []field {
+	return []field{
+		{notifier.FieldName, scheduledMaintenance.Name},
+		{notifier.FieldStatus, string(scheduledMaintenance.Status)},
+		{notifier.FieldImpact, string(scheduledMaintenance.Impact)},
+		{notifier.FieldScheduledFor, formatTime(scheduledMaintenance.ScheduledFor)},
+		{notifier.FieldScheduledUntil, formatTime(scheduledMaintenance.ScheduledUntil)},
+		{notifier.FieldComponents, formatComponents(scheduledMaintenance.Components)},
+	}
+}
+
+// field is the name and value of a single tracked field of a resource.
+type field struct {
+	name  string
+	value string
+}
+
+// idGetter will get the ID from a resource.
+type idGetter[T any] func(T) string
+
+// nameGetter will get the human readable name from a resource.
+type nameGetter[T any] func(T) string
+
+// updatedAtGetter will get the updated at time from a resource.
+type updatedAtGetter[T any] func(T) time.Time
+
+// fieldsGetter will get the tracked fields from a resource, in display order.
+type fieldsGetter[T any] func(T) []field
+
+// severityGetter will get the severity of a resource.
+type severityGetter[T any] func(T) ghstatus.Severity
+
+// finishedGetter will get whether a resource is finished, meaning resolved or completed.
+type finishedGetter[T any] func(T) bool
+
+// resourceChange is a change to a single resource between two summaries.
+type resourceChange[T any] struct {
+	// previous is the resource as it was in the last summary. This is nil if the resource is new.
+	previous *T
+
+	// current is the resource as it is in the current summary.
+	current T
+}
+
+// changeSet is the set of changes detected between two summaries.
+type changeSet struct {
+	// status is the changed status. This is nil if the status hasn't changed.
+	status *resourceChange[ghstatus.Status]
+
+	components            []resourceChange[ghstatus.Component]
+	incidents             []resourceChange[ghstatus.Incident]
+	scheduledMaintenances []resourceChange[ghstatus.ScheduledMaintenance]
+
+	// removedIncidents and removedScheduledMaintenances are resources that are no longer in the
+	// summary, as they were last seen. Once their final state has been confirmed, they are moved
+	// into the incidents and scheduled maintenances changes.
+	removedIncidents             []ghstatus.Incident
+	removedScheduledMaintenances []ghstatus.ScheduledMaintenance
+
+	// unstableComponents are components that have started flapping between statuses, and stabilizedComponents
+	// are components that have stopped. These are only populated when stabilization is enabled.
+	unstableComponents   []resourceChange[ghstatus.Component]
+	stabilizedComponents []resourceChange[ghstatus.Component]
+
+	// groupNames maps the IDs of components in a group to the name of their group.
+	groupNames map[string]string
+}
+
+// findChanges will return all of the changes between the last summary and the current summary.
+func findChanges(last ghstatus.SummaryResponse, current ghstatus.SummaryResponse) changeSet {
+	var changes changeSet
+
+	// Check to see if the status has changed.
+	if last.Status.Description != current.Status.Description || last.Status.Indicator != current.Status.Indicator {
+		changes.status = &resourceChange[ghstatus.Status]{current: current.Status}
+		if !last.Page.UpdatedAt.IsZero() {
+			changes.status.previous = &last.Status
+		}
+	}
+
+	changes.components = findChangedComponents(last.Components, current.Components)
+	changes.groupNames = findGroupNames(current.Components)
+	changes.incidents = findChangedIncidents(last.Incidents, current.Incidents)
+	changes.scheduledMaintenances = findChangedScheduledMaintenances(last.ScheduledMaintenances, current.ScheduledMaintenances)
+	changes.removedIncidents = findRemovedResources(last.Incidents, current.Incidents, getIncidentID)
+	changes.removedScheduledMaintenances = findRemovedResources(last.ScheduledMaintenances, current.ScheduledMaintenances, getScheduledMaintenanceID)
+
+	return changes
+}
+
+// empty returns true if there are no changes.
+func (c changeSet) empty() bool {
+	return c.status == nil && len(c.components) == 0 && len(c.incidents) == 0 && len(c.scheduledMaintenances) == 0 &&
+		len(c.removedIncidents) == 0 && len(c.removedScheduledMaintenances) == 0 &&
+		len(c.unstableComponents) == 0 && len(c.stabilizedComponents) == 0
+}
+
+// changeRecord is a structured change record along with the resource before and after the change.
+type changeRecord struct {
+	change notifier.Change
+
+	// before is the resource before the change. This is nil if the resource is new.
+	before any
+
+	// after is the resource after the change. This is nil if the resource was removed.
+	after any
+}
+
+// records returns a structured change record for every change in the set.
+func (c changeSet) records() []notifier.Change {
+	var records []notifier.Change
+	for _, record := range c.changeRecords() {
+		records = append(records, record.change)
+	}
+	return records
+}
+
+// changeRecords returns a structured change record for every change in the set, along with the
+// changed resources.
+func (c changeSet) changeRecords() []changeRecord {
+	var records []changeRecord
+	if c.status != nil {
+		change := toChange(notifier.KindStatus, "", "", c.status.previous, c.status.current, getStatusFields, getStatusSeverity)
+		records = append(records, withResources(change, *c.status))
+	}
+	records = append(records, c.componentRecords(c.components, "")...)
+	records = append(records, c.componentRecords(c.unstableComponents, notifier.Unstable)...)
+	records = append(records, c.componentRecords(c.stabilizedComponents, notifier.Stabilized)...)
+	for i, change := range toChanges(notifier.KindIncident, c.incidents, getIncidentID, getIncidentName, getIncidentFields, getIncidentSeverity) {
+		change.Components = ghstatus.ComponentNames(c.incidents[i].current.Components)
+		records = append(records, withResources(change, c.incidents[i]))
+	}
+	for i, change := range toChanges(notifier.KindScheduledMaintenance, c.scheduledMaintenances,
+		getScheduledMaintenanceID, getScheduledMaintenanceName, getScheduledMaintenanceFields, getScheduledMaintenanceSeverity) {
+		change.Components = ghstatus.ComponentNames(c.scheduledMaintenances[i].current.Components)
+		records = append(records, withResources(change, c.scheduledMaintenances[i]))
+	}
+	for i, change := range removedChanges(notifier.KindIncident, c.removedIncidents, getIncidentID, getIncidentName, getIncidentSeverity) {
+		change.Components = ghstatus.ComponentNames(c.removedIncidents[i].Components)
+		records = append(records, changeRecord{change: change, before: c.removedIncidents[i]})
+	}
+	for i, change := range removedChanges(notifier.KindScheduledMaintenance, c.removedScheduledMaintenances,
+		getScheduledMaintenanceID, getScheduledMaintenanceName, getScheduledMaintenanceSeverity) {
+		change.Components = ghstatus.ComponentNames(c.removedScheduledMaintenances[i].Components)
+		records = append(records, changeRecord{change: change, before: c.removedScheduledMaintenances[i]})
+	}
+	return records
+}
+
+// componentRecords returns a structured change record for each of the component changes. If a change type
+// is given, it overrides the type of each record.
+func (c changeSet) componentRecords(changes []resourceChange[ghstatus.Component], changeType notifier.ChangeType) []changeRecord {
+	var records []changeRecord
+	for i, change := range toChanges(notifier.KindComponent, changes, getComponentID, getComponentName, getComponentFields, getComponentSeverity) {
+		change.Group = c.groupNames[change.ID]
+		if changeType != "" {
+			change.Type = changeType
+		}
+		records = append(records, withResources(change, changes[i]))
+	}
+	return records
+}
+
+// withResources returns the change record along with the resource before and after the change.
+func withResources[T any](change notifier.Change, resourceChange resourceChange[T]) changeRecord {
+	record := changeRecord{change: change, after: resourceChange.current}
+	if resourceChange.previous != nil {
+		record.before = *resourceChange.previous
+	}
+	return record
+}
+
+// changedComponents returns the current state of every changed component, including those that have
+// become unstable or stabilized.
+func (c changeSet) changedComponents() []ghstatus.Component {
+	var components []ghstatus.Component
+	components = append(components, currentResources(c.components)...)
+	components = append(components, currentResources(c.unstableComponents)...)
+	components = append(components, currentResources(c.stabilizedComponents)...)
+	return components
+}
+
+// message returns the notification message for the changes on the given page.
+func (c changeSet) message(page string) notifier.Message {
+	msg := notifier.Message{
+		Page:                         page,
+		ChangedComponents:            c.changedComponents(),
+		ChangedIncidents:             currentResources(c.incidents),
+		ChangedScheduledMaintenances: currentResources(c.scheduledMaintenances),
+		Changes:                      c.records(),
+	}
+	if c.status != nil {
+		status := c.status.current
+		msg.ChangedStatus = &status
+	}
+	return msg
+}
+
+// findChangedComponents will return any components which have changed from the last known state.
+// Components are keyed by their ID, so a renamed component shows up as a change to its name.
+func findChangedComponents(last []ghstatus.Component, current []ghstatus.Component) []resourceChange[ghstatus.Component] {
+	return findChangedResources(last, current, getComponentID, getComponentUpdatedAt)
+}
+
+// findGroupNames will return a map of component IDs to the name of the group they belong to.
+func findGroupNames(components []ghstatus.Component) map[string]string {
+	groupNames := map[string]string{}
+	for _, component := range components {
+		if group, ok := ghstatus.ComponentGroup(components, component); ok {
+			groupNames[component.ID] = group.Name
+		}
+	}
+	return groupNames
+}
+
+// findChangedIncidents will return any incidents which have changed from the last known state.
+func findChangedIncidents(last []ghstatus.Incident, current []ghstatus.Incident) []resourceChange[ghstatus.Incident] {
+	return findChangedResources(last, current, getIncidentID, getIncidentUpdatedAt)
+}
+
+// findChangedScheduledMaintenances will return any scheduled maintenances which have changed from the last known state.
+func findChangedScheduledMaintenances(last []ghstatus.ScheduledMaintenance, current []ghstatus.ScheduledMaintenance) []resourceChange[ghstatus.ScheduledMaintenance] {
+	return findChangedResources(last, current, getScheduledMaintenanceID, getScheduledMaintenanceUpdatedAt)
+}
+
+// findChangedResources will return any resources which have changed from the last known state.
+func findChangedResources[T any](last []T, current []T, idGetter idGetter[T], updatedAtGetter updatedAtGetter[T]) []resourceChange[T] {
+	lastMap := map[string]T{}
+	for _, resource := range last {
+		lastMap[idGetter(resource)] = resource
+	}
+
+	var changedResources []resourceChange[T]
+	// Compare the current list of resources to see if any of them are updated.
+	// Disappearing resources are found separately by findRemovedResources.
+	for _, resource := range current {
+		resourceID := idGetter(resource)
+
+		// If this resource isn't present in the last map, then this is new.
+		lastResource, ok := lastMap[resourceID]
+		if !ok {
+			changedResources = append(changedResources, resourceChange[T]{current: resource})
+			continue
+		}
+
+		// If the updated field has changed, then the resource has changed.
+		if !updatedAtGetter(lastResource).Equal(updatedAtGetter(resource)) {
+			changedResources = append(changedResources, resourceChange[T]{previous: &lastResource, current: resource})
+		}
+	}
+
+	return changedResources
+}
+
+// findRemovedResources will return any resources from the last known state that are no longer present.
+func findRemovedResources[T any](last []T, current []T, idGetter idGetter[T]) []T {
+	currentIDs := map[string]struct{}{}
+	for _, resource := range current {
+		currentIDs[idGetter(resource)] = struct{}{}
+	}
+
+	var removedResources []T
+	for _, resource := range last {
+		if _, ok := currentIDs[idGetter(resource)]; !ok {
+			removedResources = append(removedResources, resource)
+		}
+	}
+	return removedResources
+}
+
+// confirmRemovedResources looks up the final state of each removed resource in the given list of resources.
+// Removed resources that were found are returned as changes, while those that weren't are returned as still removed.
+// Resources that were found unchanged are dropped if they were already finished when they were last seen, since
+// that has been announced, and are otherwise returned as still removed.
+func confirmRemovedResources[T any](removed []T, all []T, idGetter idGetter[T], fieldsGetter fieldsGetter[T],
+	finishedGetter finishedGetter[T]) ([]resourceChange[T], []T) {
+	allMap := map[string]T{}
+	for _, resource := range all {
+		allMap[idGetter(resource)] = resource
+	}
+
+	var confirmed []resourceChange[T]
+	var unconfirmed []T
+	for _, resource := range removed {
+		resource := resource
+		final, ok := allMap[idGetter(resource)]
+		if !ok {
+			unconfirmed = append(unconfirmed, resource)
+			continue
+		}
+		if sameFields(fieldsGetter(resource), fieldsGetter(final)) {
+			if !finishedGetter(final) {
+				unconfirmed = append(unconfirmed, resource)
+			}
+			continue
+		}
+		confirmed = append(confirmed, resourceChange[T]{previous: &resource, current: final})
+	}
+	return confirmed, unconfirmed
+}
+
+// sameFields returns true if the fields have the same values.
+func sameFields(previous []field, current []field) bool {
+	if len(previous) != len(current) {
+		return false
+	}
+	for i := range previous {
+		if previous[i] != current[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// currentResources returns the current version of each changed resource.
+func currentResources[T any](changes []resourceChange[T]) []T {
+	var resources []T
+	for _, change := range changes {
+		resources = append(resources, change.current)
+	}
+	return resources
+}
+
+// toChanges returns a structured change record for each of the resource changes.
+func toChanges[T any](kind notifier.Kind, changes []resourceChange[T], idGetter idGetter[T], nameGetter nameGetter[T],
+	fieldsGetter fieldsGetter[T], severityGetter severityGetter[T]) []notifier.Change {
+	var records []notifier.Change
+	for _, change := range changes {
+		records = append(records, toChange(kind, idGetter(change.current), nameGetter(change.current), change.previous, change.current,
+			fieldsGetter, severityGetter))
+	}
+	return records
+}
+
+// toChange returns a structured change record for a single resource. A nil previous value designates
+// an added resource.
+func toChange[T any](kind notifier.Kind, id string, name string, previous *T, current T, fieldsGetter fieldsGetter[T],
+	severityGetter severityGetter[T]) notifier.Change {
+	record := notifier.Change{
+		Kind:     kind,
+		Type:     notifier.Updated,
+		ID:       id,
+		Name:     name,
+		Severity: severityGetter(current),
+	}
+	if previous == nil {
+		record.Type = notifier.Added
+	} else {
+		record.Severity = ghstatus.MaxSeverity(record.Severity, severityGetter(*previous))
+	}
+
+	currentFields := fieldsGetter(current)
+	var previousFields []field
+	if previous != nil {
+		previousFields = fieldsGetter(*previous)
+	}
+
+	for i, currentField := range currentFields {
+		if previous == nil {
+			if currentField.value != "" {
+				record.Fields = append(record.Fields, notifier.FieldChange{Field: currentField.name, Current: currentField.value})
+			}
+			continue
+		}
+
+		if previousFields[i].value != currentField.value {
+			record.Fields = append(record.Fields, notifier.FieldChange{
+				Field:    currentField.name,
+				Previous: previousFields[i].value,
+				Current:  currentField.value,
+			})
+		}
+	}
+
+	if statusChange, ok := record.Field(notifier.FieldStatus); ok && previous != nil {
+		switch {
+		case kind == notifier.KindIncident && ghstatus.IncidentStatus(statusChange.Current).Resolved():
+			record.Type = notifier.Resolved
+		case kind == notifier.KindScheduledMaintenance && statusChange.Current == string(ghstatus.Completed):
+			record.Type = notifier.Completed
+		}
+	}
+
+	return record
+}
+
+// removedChanges returns a structured change record for each resource that has been removed without its
+// final state being confirmed.
+func removedChanges[T any](kind notifier.Kind, removed []T, idGetter idGetter[T], nameGetter nameGetter[T],
+	severityGetter severityGetter[T]) []notifier.Change {
+	var records []notifier.Change
+	for _, resource := range removed {
+		records = append(records, notifier.Change{
+			Kind:     kind,
+			Type:     notifier.Removed,
+			ID:       idGetter(resource),
+			Name:     nameGetter(resource),
+			Severity: severityGetter(resource),
+		})
+	}
+	return records
+}
+
+// formatTime formats a time for use as a field value. Times that aren't set are left empty.
+func formatTime(t ghstatus.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+// formatComponents formats the names of the given components for use as a field value. The names are
+// sorted so that reordering the components isn't reported as a change.
+func formatComponents(components []ghstatus.Component) string {
+	names := ghstatus.ComponentNames(components)
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindChangesRecordsFieldTransitions(t *testing.T) {
+	before := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	after := before.Add(time.Minute)
+
+	last := ghstatus.SummaryResponse{
+		Page:   ghstatus.Page{UpdatedAt: before},
+		Status: ghstatus.Status{Indicator: ghstatus.Minor, Description: "Partially Degraded Service"},
+		Components: []ghstatus.Component{
+			{ID: "actions", Name: "Actions", Status: ghstatus.Operational, UpdatedAt: before},
+			{ID: "packages", Name: "Packages", Status: ghstatus.Operational, UpdatedAt: before},
+		},
+		Incidents: []ghstatus.Incident{
+			{ID: "incident", Name: "Actions outage", Status: ghstatus.Investigating, Impact: ghstatus.Minor, UpdatedAt: before},
+		},
+	}
+	current := ghstatus.SummaryResponse{
+		Page:   ghstatus.Page{UpdatedAt: after},
+		Status: ghstatus.Status{Indicator: ghstatus.Major, Description: "Partial System Outage"},
+		Components: []ghstatus.Component{
+			{ID: "actions", Name: "Actions", Status: ghstatus.MajorOutage, UpdatedAt: after},
+			{ID: "packages", Name: "Packages", Status: ghstatus.Operational, UpdatedAt: before},
+		},
+		Incidents: []ghstatus.Incident{
+			{ID: "incident", Name: "Actions outage", Status: ghstatus.Identified, Impact: ghstatus.Major, UpdatedAt: after},
+		},
+	}
+
+	require.Equal(t, []notifier.Change{
+		{
+			Kind:     notifier.KindStatus,
+			Type:     notifier.Updated,
+			Severity: ghstatus.SeverityMajor,
+			Fields: []notifier.FieldChange{
+				{Field: notifier.FieldIndicator, Previous: "minor", Current: "major"},
+				{Field: notifier.FieldDescription, Previous: "Partially Degraded Service", Current: "Partial System Outage"},
+			},
+		},
+		{
+			Kind:     notifier.KindComponent,
+			Type:     notifier.Updated,
+			ID:       "actions",
+			Name:     "Actions",
+			Severity: ghstatus.SeverityCritical,
+			Fields: []notifier.FieldChange{
+				{Field: notifier.FieldStatus, Previous: "operational", Current: "major_outage"},
+			},
+		},
+		{
+			Kind:     notifier.KindIncident,
+			Type:     notifier.Updated,
+			ID:       "incident",
+			Name:     "Actions outage",
+			Severity: ghstatus.SeverityMajor,
+			Fields: []notifier.FieldChange{
+				{Field: notifier.FieldStatus, Previous: "investigating", Current: "identified"},
+				{Field: notifier.FieldImpact, Previous: "minor", Current: "major"},
+			},
+		},
+	}, findChanges(last, current).records())
+}
+
+func TestFindChangesDetectsRenames(t *testing.T) {
+	before := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	after := before.Add(time.Minute)
+
+	last := ghstatus.SummaryResponse{
+		Page: ghstatus.Page{UpdatedAt: before},
+		Components: []ghstatus.Component{
+			{ID: "actions", Name: "Actions", Status: ghstatus.Operational, UpdatedAt: before},
+		},
+	}
+	current := ghstatus.SummaryResponse{
+		Page: ghstatus.Page{UpdatedAt: after},
+		Components: []ghstatus.Component{
+			{ID: "actions", Name: "GitHub Actions", Status: ghstatus.Operational, UpdatedAt: after},
+		},
+	}
+
+	records := findChanges(last, current).records()
+	require.Len(t, records, 1)
+	previous, currentName, ok := records[0].Renamed()
+	require.True(t, ok)
+	require.Equal(t, "Actions", previous)
+	require.Equal(t, "GitHub Actions", currentName)
+	require.Equal(t, notifier.Updated, records[0].Type)
+}
+
+func TestFindChangesIncludesComponentGroup(t *testing.T) {
+	before := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	after := before.Add(time.Minute)
+
+	last := ghstatus.SummaryResponse{
+		Page: ghstatus.Page{UpdatedAt: before},
+		Components: []ghstatus.Component{
+			{ID: "cicd", Name: "CI/CD", Group: true, Components: []string{"actions"}, UpdatedAt: before},
+			{ID: "actions", Name: "Actions", GroupID: "cicd", Status: ghstatus.Operational, UpdatedAt: before},
+		},
+	}
+	current := ghstatus.SummaryResponse{
+		Page: ghstatus.Page{UpdatedAt: after},
+		Components: []ghstatus.Component{
+			{ID: "cicd", Name: "CI/CD", Group: true, Components: []string{"actions"}, UpdatedAt: before},
+			{ID: "actions", Name: "Actions", GroupID: "cicd", Status: ghstatus.DegradedPerformance, UpdatedAt: after},
+		},
+	}
+
+	records := findChanges(last, current).records()
+	require.Len(t, records, 1)
+	require.Equal(t, "actions", records[0].ID)
+	require.Equal(t, "CI/CD", records[0].Group)
+}
@@ -0,0 +1,73 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// Digest configures notifiers to receive changes in periodic summaries rather than as they're found.
+type Digest struct {
+	// Notifiers are the names of the notifiers to deliver digests to. A digest without notifiers applies to
+	// every notifier that isn't given its own digest.
+	Notifiers []string `mapstructure:"notifiers" json:"notifiers,omitempty" yaml:"notifiers,omitempty"`
+
+	// Interval is how long changes are collected for, starting from the first change, before they're delivered.
+	Interval time.Duration `mapstructure:"interval" json:"interval" yaml:"interval"`
+}
+
+// digestIntervals returns the digest interval of each notifier from the given digests. The interval for
+// notifiers without their own digest is keyed by the empty string.
+func digestIntervals(digests []Digest) map[string]time.Duration {
+	intervals := map[string]time.Duration{}
+	for _, digest := range digests {
+		if digest.Interval <= 0 {
+			continue
+		}
+		if len(digest.Notifiers) == 0 {
+			intervals[""] = digest.Interval
+			continue
+		}
+		for _, name := range digest.Notifiers {
+			intervals[name] = digest.Interval
+		}
+	}
+	return intervals
+}
+
+// digestInterval returns the digest interval of the notifier, or zero if it isn't in digest mode.
+func (m *Monitor) digestInterval(name string) time.Duration {
+	if interval, ok := m.digests[name]; ok {
+		return interval
+	}
+	return m.digests[""]
+}
+
+// collect adds the message to the notifier's digest. The digest is due an interval after the first change
+// collected into it. Changes that a later change undoes are dropped from the digest.
+func (p *page) collect(name string, msg notifier.Message, now time.Time, interval time.Duration) {
+	digest, ok := p.digests[name]
+	if !ok {
+		digest.Due = now.Add(interval)
+	}
+	digest.Message = digest.Message.Merge(msg)
+	if digest.Message.Empty() {
+		// Everything collected so far has been undone, so there's nothing left to deliver.
+		delete(p.digests, name)
+		return
+	}
+	p.digests[name] = digest
+}
+
+// dueDigests removes and returns the digests that are due at the given time, keyed by notifier name.
+func (p *page) dueDigests(now time.Time) map[string]notifier.Message {
+	due := map[string]notifier.Message{}
+	for name, digest := range p.digests {
+		if now.Before(digest.Due) {
+			continue
+		}
+		due[name] = digest.Message
+		delete(p.digests, name)
+	}
+	return due
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMonitorDeliversDigests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clock := clockwork.NewFakeClock()
+	server, testClient := ghstatus.NewTestServerAndClient(t)
+	client := &pollingClient{Client: testClient, polls: make(chan struct{}, 10)}
+
+	m := New(zap.NewNop(), clock, false, WithDigests(Digest{Interval: 15 * time.Minute}))
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+
+	updatedAt := clock.Now().UTC()
+	component := func(id string, status ghstatus.ComponentStatus) ghstatus.Component {
+		return ghstatus.Component{ID: id, Name: id, Status: status, UpdatedAt: updatedAt.Add(time.Minute)}
+	}
+	setComponents := func(components ...ghstatus.Component) {
+		updatedAt = updatedAt.Add(time.Minute)
+		server.SetSummary(t, ghstatus.SummaryResponse{
+			Page:       ghstatus.Page{UpdatedAt: updatedAt},
+			Components: components,
+		})
+	}
+	poll := func() {
+		clock.Advance(5 * time.Minute)
+		<-client.polls
+		select {
+		case msg := <-ch:
+			require.Failf(t, "unexpected notification", "%+v", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	setComponents(component("actions", ghstatus.Operational), component("pages", ghstatus.Operational))
+	go m.MonitorAndNotify(ctx, 5*time.Minute)
+	<-client.polls
+
+	setComponents(component("actions", ghstatus.DegradedPerformance), component("pages", ghstatus.Operational))
+	poll()
+
+	outage := component("actions", ghstatus.MajorOutage)
+	pages := component("pages", ghstatus.DegradedPerformance)
+	setComponents(outage, pages)
+	poll()
+	poll()
+
+	// The digest is delivered 15 minutes after the first change, with the changes to each component merged.
+	clock.Advance(5 * time.Minute)
+	msg := waitForNotification(t, ch)
+	require.Equal(t, []ghstatus.Component{outage, pages}, msg.ChangedComponents)
+	require.Equal(t, []notifier.Change{
+		{
+			Kind:     notifier.KindComponent,
+			Type:     notifier.Updated,
+			ID:       "actions",
+			Name:     "actions",
+			Severity: ghstatus.SeverityCritical,
+			Fields: []notifier.FieldChange{
+				{Field: notifier.FieldStatus, Previous: "operational", Current: "major_outage"},
+			},
+		},
+		{
+			Kind:     notifier.KindComponent,
+			Type:     notifier.Updated,
+			ID:       "pages",
+			Name:     "pages",
+			Severity: ghstatus.SeverityMinor,
+			Fields: []notifier.FieldChange{
+				{Field: notifier.FieldStatus, Previous: "operational", Current: "degraded_performance"},
+			},
+		},
+	}, msg.Changes)
+}
+
+func TestCollectDropsRevertedChanges(t *testing.T) {
+	_, client := ghstatus.NewTestServerAndClient(t)
+	p := newPage(zap.NewNop(), client)
+	now := clockwork.NewFakeClock().Now()
+
+	statusChange := func(id string, previous, current ghstatus.ComponentStatus) notifier.Message {
+		return notifier.Message{
+			ChangedComponents: []ghstatus.Component{{ID: id, Name: id, Status: current}},
+			Changes: []notifier.Change{
+				{
+					Kind: notifier.KindComponent, Type: notifier.Updated, ID: id, Name: id,
+					Fields: []notifier.FieldChange{
+						{Field: notifier.FieldStatus, Previous: string(previous), Current: string(current)},
+					},
+				},
+			},
+		}
+	}
+
+	p.collect("slack", statusChange("actions", ghstatus.Operational, ghstatus.MajorOutage), now, time.Hour)
+	p.collect("slack", statusChange("pages", ghstatus.Operational, ghstatus.DegradedPerformance), now, time.Hour)
+	p.collect("slack", statusChange("actions", ghstatus.MajorOutage, ghstatus.Operational), now, time.Hour)
+
+	// The actions outage was undone within the digest, so only the pages change is left.
+	digest := p.digests["slack"]
+	require.Equal(t, []ghstatus.Component{{ID: "pages", Name: "pages", Status: ghstatus.DegradedPerformance}},
+		digest.Message.ChangedComponents)
+	require.Len(t, digest.Message.Changes, 1)
+	require.Equal(t, "pages", digest.Message.Changes[0].ID)
+
+	// Once every change has been undone, the digest is dropped altogether.
+	p.collect("slack", statusChange("pages", ghstatus.DegradedPerformance, ghstatus.Operational), now, time.Hour)
+	require.NotContains(t, p.digests, "slack")
+}
+
+func TestDigestIntervals(t *testing.T) {
+	m := New(zap.NewNop(), clockwork.NewFakeClock(), false, WithDigests(
+		Digest{Interval: time.Hour},
+		Digest{Notifiers: []string{"slack"}, Interval: 15 * time.Minute},
+	))
+	require.Equal(t, 15*time.Minute, m.digestInterval("slack"))
+	require.Equal(t, time.Hour, m.digestInterval("file"))
+
+	require.Zero(t, New(zap.NewNop(), clockwork.NewFakeClock(), false).digestInterval("slack"))
+}
@@ -1,5 +1,6 @@
 // Package monitor contains the Github Status monitor.
 //
-// The monitor will observe the output from the Github Status API
-// and report any detected differences to configured notifiers.
+// The monitor will observe the output from the Github Status API, or any other
+// Statuspage-hosted page, and report any detected differences to configured notifiers.
+// Several pages can be monitored at once, each keeping its own state.
 package monitor
@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/escalation"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/mdwn/ghstatus/pkg/state"
+	"go.uber.org/zap"
+)
+
+// escalate re-notifies about the unresolved incidents on the page whose escalations are due. Incidents are
+// tracked from the first time they're seen unresolved until they're resolved or no longer listed. The
+// escalations already due for incidents that were listed before the page started being monitored are
+// recorded without being sent, the same way their other changes aren't notified.
+func (m *Monitor) escalate(ctx context.Context, p *page) error {
+	if m.escalations == nil {
+		return nil
+	}
+
+	// The baseline only applies to the first time incidents are tracked after the page started being monitored.
+	baseline := p.baselineIncidents
+	p.baselineIncidents = nil
+
+	now := m.clock.Now()
+	changed := false
+	var errs []error
+
+	unresolved := map[string]struct{}{}
+	for _, incident := range p.lastSummary.Incidents {
+		if incident.Status.Resolved() {
+			continue
+		}
+		unresolved[incident.ID] = struct{}{}
+
+		tracked, ok := p.escalations[incident.ID]
+		if !ok {
+			tracked = state.IncidentEscalation{Since: unresolvedSince(incident, now)}
+			if _, ok := baseline[incident.ID]; ok {
+				for _, step := range m.escalations.Due(incident.Severity(), now.Sub(tracked.Since), nil) {
+					tracked.Escalated = append(tracked.Escalated, step.ID)
+				}
+				if len(tracked.Escalated) > 0 {
+					p.log.With(zap.String("incident", incident.ID)).Debug("Incident was already unresolved, skipping the escalations already due.")
+				}
+			}
+			p.escalations[incident.ID] = tracked
+			changed = true
+		}
+
+		unresolvedFor := now.Sub(tracked.Since)
+		for _, step := range m.escalations.Due(incident.Severity(), unresolvedFor, tracked.Escalated) {
+			p.log.With(zap.String("incident", incident.ID), zap.String("escalation", step.ID)).Debug("Escalating incident.")
+			tracked.Escalated = append(tracked.Escalated, step.ID)
+			p.escalations[incident.ID] = tracked
+			changed = true
+			if err := m.notifyEscalation(ctx, p, incident, step, unresolvedFor); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for id := range p.escalations {
+		if _, ok := unresolved[id]; !ok {
+			delete(p.escalations, id)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := p.save(ctx, m.stateStore); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyEscalation sends the escalation of the incident to the notifiers named by the escalation, or to the
+// notifiers the incident is routed to if it doesn't name any.
+func (m *Monitor) notifyEscalation(ctx context.Context, p *page, incident ghstatus.Incident, step escalation.Step,
+	unresolvedFor time.Duration) error {
+	msg := notifier.Message{
+		Page:             p.name,
+		ChangedIncidents: []ghstatus.Incident{incident},
+		Changes: []notifier.Change{
+			{
+				Kind:       notifier.KindIncident,
+				Type:       notifier.Escalated,
+				ID:         incident.ID,
+				Name:       incident.Name,
+				Components: ghstatus.ComponentNames(incident.Components),
+				Severity:   incident.Severity(),
+				Fields: []notifier.FieldChange{
+					{Field: notifier.FieldStatus, Current: string(incident.Status)},
+					{Field: notifier.FieldImpact, Current: string(incident.Impact)},
+					{Field: notifier.FieldUnresolvedFor, Current: formatDuration(unresolvedFor.Round(time.Minute))},
+				},
+			},
+		},
+	}
+	if m.filter != nil {
+		msg = msg.Filter(m.filter.Matches)
+		if msg.Empty() {
+			return nil
+		}
+	}
+
+	p.notifyMu.Lock()
+	defer p.notifyMu.Unlock()
+	notifiers := m.registeredNotifiers()
+
+	if len(step.Notifiers) == 0 {
+		return m.deliver(ctx, p, notifiers, m.route(msg, notifiers))
+	}
+
+	messages := make(map[string]notifier.Message, len(step.Notifiers))
+	for _, name := range step.Notifiers {
+		messages[name] = msg
+	}
+	return m.deliver(ctx, p, notifiers, messages)
+}
+
+// formatDuration formats the duration without trailing zero units, e.g. 2h rather than 2h0m0s.
+func formatDuration(d time.Duration) string {
+	formatted := d.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+	return formatted
+}
+
+// unresolvedSince returns when the incident became unresolved, which is when it was created. Incidents
+// without a creation time are treated as unresolved since now.
+func unresolvedSince(incident ghstatus.Incident, now time.Time) time.Time {
+	if incident.CreatedAt.IsZero() || incident.CreatedAt.After(now) {
+		return now
+	}
+	return incident.CreatedAt
+}
@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/escalation"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/mdwn/ghstatus/pkg/state"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEscalate(t *testing.T) {
+	ctx := context.Background()
+	clock := clockwork.NewFakeClock()
+	store := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	_, client := ghstatus.NewTestServerAndClient(t)
+
+	policies, err := escalation.New([]escalation.Policy{
+		{Name: "still-down", MinSeverity: "major", After: 30 * time.Minute},
+	})
+	require.NoError(t, err)
+
+	newMonitor := func() (*Monitor, chan notifier.Message) {
+		m := New(zap.NewNop(), clock, false, WithEscalations(policies), WithStateStore(store))
+		ch := make(chan notifier.Message, 1)
+		require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+		return m, ch
+	}
+
+	incident := ghstatus.Incident{
+		ID:         "incident",
+		Name:       "Actions are slow",
+		Status:     ghstatus.Investigating,
+		Impact:     ghstatus.Major,
+		Components: []ghstatus.Component{{ID: "actions", Name: "Actions"}},
+	}
+
+	m, _ := newMonitor()
+	p := newPage(m.log, client)
+	p.lastSummary = ghstatus.SummaryResponse{Page: ghstatus.Page{UpdatedAt: clock.Now()}, Incidents: []ghstatus.Incident{incident}}
+	require.NoError(t, m.escalate(ctx, p))
+
+	// The time the incident was first seen survives a restart.
+	clock.Advance(20 * time.Minute)
+	m, ch := newMonitor()
+	p = newPage(m.log, client)
+	require.NoError(t, p.load(ctx, store))
+	require.NoError(t, m.escalate(ctx, p))
+	require.Empty(t, ch)
+
+	clock.Advance(10 * time.Minute)
+	require.NoError(t, m.escalate(ctx, p))
+	msg := <-ch
+	require.Equal(t, notifier.Message{
+		Page:             ghstatus.PageName(client),
+		ChangedIncidents: []ghstatus.Incident{incident},
+		Changes: []notifier.Change{
+			{
+				Kind:       notifier.KindIncident,
+				Type:       notifier.Escalated,
+				ID:         "incident",
+				Name:       "Actions are slow",
+				Components: []string{"Actions"},
+				Severity:   ghstatus.SeverityMajor,
+				Fields: []notifier.FieldChange{
+					{Field: notifier.FieldStatus, Current: "investigating"},
+					{Field: notifier.FieldImpact, Current: "major"},
+					{Field: notifier.FieldUnresolvedFor, Current: "30m"},
+				},
+			},
+		},
+	}, msg)
+
+	// Each escalation only fires once.
+	clock.Advance(10 * time.Minute)
+	require.NoError(t, m.escalate(ctx, p))
+	require.Empty(t, ch)
+
+	// Resolved incidents are no longer tracked.
+	incident.Status = ghstatus.Resolved
+	p.lastSummary.Incidents = []ghstatus.Incident{incident}
+	require.NoError(t, m.escalate(ctx, p))
+	pageState, _, err := store.Load(ctx, ghstatus.PageName(client))
+	require.NoError(t, err)
+	require.Empty(t, pageState.Escalations)
+
+	// Incidents are unresolved since they were created, even if the monitor only sees them later.
+	incident.Status = ghstatus.Investigating
+	incident.CreatedAt = clock.Now().Add(-2 * time.Hour)
+	p.lastSummary.Incidents = []ghstatus.Incident{incident}
+	require.NoError(t, m.escalate(ctx, p))
+	msg = <-ch
+	unresolvedFor, ok := msg.Changes[0].Field(notifier.FieldUnresolvedFor)
+	require.True(t, ok)
+	require.Equal(t, "2h", unresolvedFor.Current)
+}
+
+func TestMonitorSkipsEscalationsDueOnStartup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clock := clockwork.NewFakeClock()
+	server, testClient := ghstatus.NewTestServerAndClient(t)
+	client := &pollingClient{Client: testClient, polls: make(chan struct{}, 10)}
+
+	policies, err := escalation.New([]escalation.Policy{
+		{Name: "still-down", After: 30 * time.Minute},
+		{Name: "still-down-for-hours", After: 4 * time.Hour},
+	})
+	require.NoError(t, err)
+
+	m := New(zap.NewNop(), clock, false, WithEscalations(policies))
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page: ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Incidents: []ghstatus.Incident{
+			{
+				ID:        "incident",
+				Name:      "Actions are slow",
+				Status:    ghstatus.Investigating,
+				Impact:    ghstatus.Major,
+				CreatedAt: clock.Now().Add(-3 * time.Hour),
+			},
+		},
+	})
+
+	// The incident was already unresolved for 3 hours when the monitor started, so the escalation that was
+	// due after 30 minutes isn't sent.
+	go m.MonitorAndNotify(ctx, time.Hour)
+	<-client.polls
+	clock.BlockUntil(1)
+	select {
+	case msg := <-ch:
+		require.Failf(t, "unexpected notification", "%+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Escalations that become due later are still sent.
+	clock.Advance(time.Hour)
+	<-client.polls
+	msg := waitForNotification(t, ch)
+	require.Equal(t, notifier.Escalated, msg.Changes[0].Type)
+	unresolvedFor, ok := msg.Changes[0].Field(notifier.FieldUnresolvedFor)
+	require.True(t, ok)
+	require.Equal(t, "4h", unresolvedFor.Current)
+}
+
+func TestFormatDuration(t *testing.T) {
+	require.Equal(t, "30m", formatDuration(30*time.Minute))
+	require.Equal(t, "2h", formatDuration(2*time.Hour))
+	require.Equal(t, "1h30m", formatDuration(90*time.Minute))
+	require.Equal(t, "45s", formatDuration(45*time.Second))
+}
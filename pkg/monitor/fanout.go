@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultNotifyTimeout is the default time each notifier is given to deliver a message.
+	DefaultNotifyTimeout = 30 * time.Second
+)
+
+// send is a message to send with a notifier.
+type send struct {
+	notifier notifier.Notifier
+	msg      notifier.Message
+}
+
+// notifyResult is the outcome of a send. For notifiers that queue messages, a send without an error only
+// means that the message was queued.
+type notifyResult struct {
+	notifier string
+	queued   bool
+	duration time.Duration
+	err      error
+}
+
+// fanOut sends every message concurrently, giving each notifier up to the notify timeout and recovering from
+// panics so that a misbehaving notifier can't hold up or take down the others. A notifier still stuck in a
+// call that timed out is skipped. The errors of the notifiers
+// that failed are returned.
+func (m *Monitor) fanOut(ctx context.Context, p *page, sends []send) error {
+	results := make([]notifyResult, len(sends))
+
+	var wg sync.WaitGroup
+	for i, s := range sends {
+		wg.Add(1)
+		go func(i int, s send) {
+			defer wg.Done()
+			start := m.clock.Now()
+			err := m.deliverer.Deliver(ctx, s.notifier, s.msg, m.notifyTimeout)
+			queuing, ok := s.notifier.(notifier.Queuing)
+			results[i] = notifyResult{
+				notifier: s.notifier.Name(),
+				queued:   ok && queuing.Queues(),
+				duration: m.clock.Since(start),
+				err:      err,
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("error notifying %s after %s: %w", result.notifier, result.duration, result.err))
+			continue
+		}
+		log := p.log.With(zap.String("notifier", result.notifier), zap.Duration("duration", result.duration))
+		if result.queued {
+			log.Debug("Queued for delivery.")
+			continue
+		}
+		log.Debug("Notified.")
+	}
+	if stuck := m.deliverer.Stuck(); stuck > 0 {
+		p.log.With(zap.Int("calls", stuck)).Warn("notifier calls are still running after timing out")
+	}
+	return errors.Join(errs...)
+}
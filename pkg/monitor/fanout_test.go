@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// hangingNotifier never returns from Notify until it's released, ignoring the context.
+type hangingNotifier struct {
+	release chan struct{}
+}
+
+func (h *hangingNotifier) Name() string { return "hanging" }
+func (h *hangingNotifier) Cleanup() error {
+	close(h.release)
+	return nil
+}
+func (h *hangingNotifier) Notify(_ context.Context, _ notifier.Message) error {
+	<-h.release
+	return nil
+}
+
+// panickingNotifier panics on every notification.
+type panickingNotifier struct{}
+
+func (panickingNotifier) Name() string   { return "panicking" }
+func (panickingNotifier) Cleanup() error { return nil }
+func (panickingNotifier) Notify(_ context.Context, _ notifier.Message) error {
+	panic("boom")
+}
+
+func TestNotifyIsolatesNotifiers(t *testing.T) {
+	_, client := ghstatus.NewTestServerAndClient(t)
+	m := New(zap.NewNop(), clockwork.NewFakeClock(), false, WithNotifyTimeout(50*time.Millisecond))
+
+	hanging := &hangingNotifier{release: make(chan struct{})}
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(hanging))
+	require.NoError(t, m.RegisterNotifier(panickingNotifier{}))
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+
+	msg := notifier.Message{
+		Page:    ghstatus.PageName(client),
+		Changes: []notifier.Change{{Kind: notifier.KindStatus, Type: notifier.Updated}},
+	}
+	err := m.notify(context.Background(), newPage(m.log, client), msg)
+	require.ErrorContains(t, err, "error notifying hanging")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "notifier panicking panicked: boom")
+
+	// The other notifiers didn't keep the working one from being notified.
+	require.Equal(t, msg, <-ch)
+
+	// The hanging notifier isn't called again while its last call is still running.
+	require.Equal(t, 1, m.deliverer.Stuck())
+	err = m.notify(context.Background(), newPage(m.log, client), msg)
+	require.ErrorIs(t, err, notifier.ErrBusy)
+	require.Equal(t, msg, <-ch)
+
+	require.NoError(t, hanging.Cleanup())
+	require.Eventually(t, func() bool { return m.deliverer.Stuck() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+// pageBlockingNotifier blocks notifications for one page until it's released, and sends every message to its
+// channel once it's delivered.
+type pageBlockingNotifier struct {
+	page    string
+	blocked chan struct{}
+	release chan struct{}
+	ch      chan notifier.Message
+}
+
+func (b *pageBlockingNotifier) Name() string   { return "page-blocking" }
+func (b *pageBlockingNotifier) Cleanup() error { return nil }
+func (b *pageBlockingNotifier) Notify(_ context.Context, msg notifier.Message) error {
+	if msg.Page == b.page {
+		close(b.blocked)
+		<-b.release
+	}
+	b.ch <- msg
+	return nil
+}
+
+func TestNotifyDoesNotBlockOtherPages(t *testing.T) {
+	_, client := ghstatus.NewTestServerAndClient(t)
+	m := New(zap.NewNop(), clockwork.NewFakeClock(), false, WithNotifyTimeout(0))
+
+	blocking := &pageBlockingNotifier{
+		page:    "slow",
+		blocked: make(chan struct{}),
+		release: make(chan struct{}),
+		ch:      make(chan notifier.Message, 2),
+	}
+	require.NoError(t, m.RegisterNotifier(blocking))
+
+	slow := newPage(m.log, client)
+	slow.name = "slow"
+	fast := newPage(m.log, client)
+	fast.name = "fast"
+	change := []notifier.Change{{Kind: notifier.KindStatus, Type: notifier.Updated}}
+
+	slowDone := make(chan error, 1)
+	go func() {
+		slowDone <- m.notify(context.Background(), slow, notifier.Message{Page: "slow", Changes: change})
+	}()
+	<-blocking.blocked
+
+	// The notification for the other page is delivered while the slow one is still blocked.
+	require.NoError(t, m.notify(context.Background(), fast, notifier.Message{Page: "fast", Changes: change}))
+	require.Equal(t, "fast", (<-blocking.ch).Page)
+
+	close(blocking.release)
+	require.NoError(t, <-slowDone)
+	require.Equal(t, "slow", (<-blocking.ch).Page)
+}
@@ -0,0 +1,95 @@
+package monitor
+
+import (
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// hold holds back the changes in the message that shouldn't be delivered to the notifier yet, and returns
+// the message with the changes to deliver now. Changes held back earlier for resources that are delivered
+// now are delivered along with them, so that the notifier sees the full transition.
+func (p *page) hold(name string, msg notifier.Message, holds func(notifier.Change) bool) notifier.Message {
+	held := p.held[name]
+	if len(held.Changes) == 0 && !anyChange(msg, holds) {
+		return msg
+	}
+
+	deliver := msg.Filter(func(change notifier.Change) bool { return !holds(change) })
+	delivered := map[changeKey]struct{}{}
+	for _, change := range deliver.Changes {
+		delivered[keyOf(change)] = struct{}{}
+	}
+	isDelivered := func(change notifier.Change) bool {
+		_, ok := delivered[keyOf(change)]
+		return ok
+	}
+
+	if len(delivered) > 0 {
+		deliver = held.Filter(isDelivered).Merge(deliver)
+		held = held.Filter(func(change notifier.Change) bool { return !isDelivered(change) })
+	}
+	held = held.Merge(msg.Filter(holds))
+
+	if held.Empty() {
+		delete(p.held, name)
+	} else {
+		p.held[name] = held
+	}
+	return deliver
+}
+
+// anyChange returns true if the predicate is true for any change in the message.
+func anyChange(msg notifier.Message, predicate func(notifier.Change) bool) bool {
+	for _, change := range msg.Changes {
+		if predicate(change) {
+			return true
+		}
+	}
+	return false
+}
+
+// settle returns the held message with the changes that no longer need to be delivered dropped. Incidents
+// that have been resolved and scheduled maintenances that have been completed are dropped, as are fields
+// that ended up back at their previous values and updates left without any changed fields.
+func settle(msg notifier.Message) notifier.Message {
+	var changes []notifier.Change
+	for _, change := range msg.Changes {
+		if change.Type == notifier.Resolved || change.Type == notifier.Completed {
+			continue
+		}
+		if change.Type == notifier.Updated {
+			var fields []notifier.FieldChange
+			for _, field := range change.Fields {
+				if field.Previous != field.Current {
+					fields = append(fields, field)
+				}
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			change.Fields = fields
+		}
+		changes = append(changes, change)
+	}
+
+	settled := map[changeKey]struct{}{}
+	for _, change := range changes {
+		settled[keyOf(change)] = struct{}{}
+	}
+	msg = msg.Filter(func(change notifier.Change) bool {
+		_, ok := settled[keyOf(change)]
+		return ok
+	})
+	msg.Changes = changes
+	return msg
+}
+
+// changeKey identifies a change within a message.
+type changeKey struct {
+	kind notifier.Kind
+	id   string
+}
+
+// keyOf returns the key of the change.
+func keyOf(change notifier.Change) changeKey {
+	return changeKey{kind: change.Kind, id: change.ID}
+}
@@ -0,0 +1,140 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/mdwn/ghstatus/pkg/quiet"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMonitorHoldsChangesDuringMutes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clock := clockwork.NewFakeClockAt(time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC))
+	windows, err := quiet.New(nil, []quiet.Mute{
+		{Start: "2026-10-20T10:05:00Z", End: "2026-10-20T10:30:00Z", AllowSeverity: "critical"},
+	})
+	require.NoError(t, err)
+
+	server, testClient := ghstatus.NewTestServerAndClient(t)
+	client := &pollingClient{Client: testClient, polls: make(chan struct{}, 10)}
+
+	m := New(zap.NewNop(), clock, true, WithQuietWindows(windows))
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+
+	updatedAt := clock.Now().UTC()
+	component := func(id string, status ghstatus.ComponentStatus) ghstatus.Component {
+		return ghstatus.Component{ID: id, Name: id, Status: status, UpdatedAt: updatedAt.Add(time.Minute)}
+	}
+	setComponents := func(components ...ghstatus.Component) {
+		updatedAt = updatedAt.Add(time.Minute)
+		server.SetSummary(t, ghstatus.SummaryResponse{
+			Page:       ghstatus.Page{UpdatedAt: updatedAt},
+			Components: components,
+		})
+	}
+	expectNoNotification := func() {
+		select {
+		case msg := <-ch:
+			require.Failf(t, "unexpected notification", "%+v", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	setComponents(component("actions", ghstatus.Operational), component("git", ghstatus.Operational),
+		component("pages", ghstatus.Operational))
+	go m.MonitorAndNotify(ctx, 10*time.Minute)
+	<-client.polls
+	waitForNotification(t, ch)
+
+	// Minor changes during the mute window are held back.
+	degradedActions := component("actions", ghstatus.DegradedPerformance)
+	setComponents(degradedActions, component("git", ghstatus.DegradedPerformance), component("pages", ghstatus.Operational))
+	clock.Advance(10 * time.Minute)
+	<-client.polls
+	expectNoNotification()
+
+	// Critical changes are delivered right away, and changes that revert are forgotten.
+	outage := component("pages", ghstatus.MajorOutage)
+	setComponents(degradedActions, component("git", ghstatus.Operational), outage)
+	clock.Advance(10 * time.Minute)
+	<-client.polls
+	msg := waitForNotification(t, ch)
+	require.False(t, msg.Deferred)
+	require.Equal(t, []ghstatus.Component{outage}, msg.ChangedComponents)
+
+	// Once the window ends, the remaining held changes are delivered as a summary.
+	clock.Advance(10 * time.Minute)
+	msg = waitForNotification(t, ch)
+	require.True(t, msg.Deferred)
+	require.Equal(t, []ghstatus.Component{degradedActions}, msg.ChangedComponents)
+	require.Equal(t, []notifier.Change{
+		{
+			Kind:     notifier.KindComponent,
+			Type:     notifier.Updated,
+			ID:       "actions",
+			Name:     "actions",
+			Severity: ghstatus.SeverityMinor,
+			Fields: []notifier.FieldChange{
+				{Field: notifier.FieldStatus, Previous: "operational", Current: "degraded_performance"},
+			},
+		},
+	}, msg.Changes)
+}
+
+func TestSettle(t *testing.T) {
+	incident := ghstatus.Incident{ID: "incident", Name: "Incident", Status: ghstatus.Investigating}
+	resolvedIncident := incident
+	resolvedIncident.Status = ghstatus.Resolved
+
+	held := notifier.Message{
+		Page:             ghstatus.GithubPageName,
+		ChangedIncidents: []ghstatus.Incident{incident},
+		Changes: []notifier.Change{
+			{
+				Kind: notifier.KindIncident, Type: notifier.Added, ID: "incident", Name: "Incident",
+				Fields: []notifier.FieldChange{{Field: notifier.FieldStatus, Current: "investigating"}},
+			},
+		},
+	}
+	held = held.Merge(notifier.Message{
+		Page:             ghstatus.GithubPageName,
+		ChangedIncidents: []ghstatus.Incident{resolvedIncident},
+		Changes: []notifier.Change{
+			{
+				Kind: notifier.KindIncident, Type: notifier.Resolved, ID: "incident", Name: "Incident",
+				Fields: []notifier.FieldChange{{Field: notifier.FieldStatus, Previous: "investigating", Current: "resolved"}},
+			},
+		},
+	})
+	require.Equal(t, []ghstatus.Incident{resolvedIncident}, held.ChangedIncidents)
+	require.Len(t, held.Changes, 1)
+	require.True(t, settle(held).Empty())
+
+	component := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.Operational}
+	renamed := notifier.Message{
+		ChangedComponents: []ghstatus.Component{component},
+		Changes: []notifier.Change{
+			{
+				Kind: notifier.KindComponent, Type: notifier.Updated, ID: "actions", Name: "Actions",
+				Fields: []notifier.FieldChange{
+					{Field: notifier.FieldName, Previous: "Workflows", Current: "Actions"},
+					{Field: notifier.FieldStatus, Previous: "operational", Current: "operational"},
+				},
+			},
+		},
+	}
+	settled := settle(renamed)
+	require.Equal(t, []ghstatus.Component{component}, settled.ChangedComponents)
+	require.Equal(t, []notifier.FieldChange{{Field: notifier.FieldName, Previous: "Workflows", Current: "Actions"}},
+		settled.Changes[0].Fields)
+}
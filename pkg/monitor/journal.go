@@ -0,0 +1,20 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/journal"
+)
+
+// journalEntries returns a journal entry for each change in the change set.
+func journalEntries(t time.Time, page string, changes changeSet) ([]journal.Entry, error) {
+	var entries []journal.Entry
+	for _, record := range changes.changeRecords() {
+		entry, err := journal.NewEntry(t, page, record.change, record.before, record.after)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
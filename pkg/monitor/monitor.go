@@ -8,38 +8,167 @@ import (
 	"time"
 
 	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/escalation"
+	"github.com/mdwn/ghstatus/pkg/filter"
 	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/journal"
 	"github.com/mdwn/ghstatus/pkg/logging"
 	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/mdwn/ghstatus/pkg/quiet"
+	"github.com/mdwn/ghstatus/pkg/router"
+	"github.com/mdwn/ghstatus/pkg/state"
 	"go.uber.org/zap"
 )
 
-const (
-	// this is a faux-component that shows up in the Github API. We'll filter it out when displaying changes to the user.
-	fauxComponentName = "Visit www.githubstatus.com for more information"
-)
-
-// Monitor will periodically poll the Github Status and issues updates
-// to the given callback.
+// Monitor will periodically poll one or more status pages and issues updates
+// to the registered notifiers.
 type Monitor struct {
 	log              *zap.Logger
 	clock            clockwork.Clock
-	client           ghstatus.Client
 	notifyOnFirstRun bool
+	stateStore       state.Store
+	journal          *journal.Journal
+	filter           *filter.Filter
+	router           *router.Router
+	stabilization    Stabilization
+	quiet            *quiet.Windows
+	digests          map[string]time.Duration
+	escalations      *escalation.Policies
+	notifyTimeout    time.Duration
+	polling          Polling
+	unavailableAfter int
+
+	// deliverer tracks the notifier calls that timed out.
+	deliverer notifier.Deliverer
+
+	clientsMu sync.RWMutex
+	clients   map[string]ghstatus.Client
 
 	notifiersMu sync.RWMutex
 	notifiers   map[string]notifier.Notifier
 }
 
-// New creates a new Github Status monitor.
-func New(log *zap.Logger, clock clockwork.Clock, client ghstatus.Client, notifyOnFirstRun bool) *Monitor {
-	return &Monitor{
+// Option configures optional monitor behavior.
+type Option func(*Monitor)
+
+// WithStateStore persists the state of each monitored page to the given store so that
+// the monitor can pick up where it left off after a restart.
+func WithStateStore(store state.Store) Option {
+	return func(m *Monitor) {
+		m.stateStore = store
+	}
+}
+
+// WithJournal records every detected change in the given journal.
+func WithJournal(journal *journal.Journal) Option {
+	return func(m *Monitor) {
+		m.journal = journal
+	}
+}
+
+// WithFilter only passes changes that match the given filter on to the notifiers. Changes that are
+// filtered out are still recorded in the journal.
+func WithFilter(filter *filter.Filter) Option {
+	return func(m *Monitor) {
+		m.filter = filter
+	}
+}
+
+// WithRouter routes each change to the notifiers chosen by the given router instead of sending every change
+// to every notifier.
+func WithRouter(router *router.Router) Option {
+	return func(m *Monitor) {
+		m.router = router
+	}
+}
+
+// WithStabilization holds back component status changes until they've persisted and collapses flapping
+// components into a single notification.
+func WithStabilization(stabilization Stabilization) Option {
+	return func(m *Monitor) {
+		m.stabilization = stabilization
+	}
+}
+
+// WithQuietWindows holds back changes from notifiers during their quiet hours and mute windows. Held changes
+// are delivered as a summary once the window ends, leaving out those that have since been resolved.
+func WithQuietWindows(windows *quiet.Windows) Option {
+	return func(m *Monitor) {
+		m.quiet = windows
+	}
+}
+
+// WithDigests collects the changes for the notifiers of the given digests and delivers them as a single
+// consolidated message per page once each digest's interval has passed. Repeated changes to the same
+// resource are merged.
+func WithDigests(digests ...Digest) Option {
+	return func(m *Monitor) {
+		m.digests = digestIntervals(digests)
+	}
+}
+
+// WithEscalations re-notifies about incidents that are still unresolved once the delays of the given escalation
+// policies have passed. The time each incident was first seen is kept in the state store, if there is one.
+func WithEscalations(policies *escalation.Policies) Option {
+	return func(m *Monitor) {
+		m.escalations = policies
+	}
+}
+
+// WithNotifyTimeout gives each notifier up to the given time to deliver a message. Zero or less doesn't time out.
+func WithNotifyTimeout(timeout time.Duration) Option {
+	return func(m *Monitor) {
+		m.notifyTimeout = timeout
+	}
+}
+
+// WithPolling polls pages faster while something is wrong on them, backs off while everything is operational
+// and adds jitter to the time between polls.
+func WithPolling(polling Polling) Option {
+	return func(m *Monitor) {
+		m.polling = polling
+	}
+}
+
+// WithUnavailableAfter reports a status page as unavailable to the notifiers once polling it has failed the
+// given number of times in a row, and reports its recovery once polling it works again. Zero or less never
+// reports pages as unavailable.
+func WithUnavailableAfter(failures int) Option {
+	return func(m *Monitor) {
+		m.unavailableAfter = failures
+	}
+}
+
+// New creates a new status page monitor. Status pages to watch are added with RegisterClient.
+func New(log *zap.Logger, clock clockwork.Clock, notifyOnFirstRun bool, opts ...Option) *Monitor {
+	m := &Monitor{
 		log:              logging.WithComponent(log, "monitor"),
 		clock:            clock,
-		client:           client,
 		notifyOnFirstRun: notifyOnFirstRun,
+		notifyTimeout:    DefaultNotifyTimeout,
+		clients:          map[string]ghstatus.Client{},
 		notifiers:        map[string]notifier.Notifier{},
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// RegisterClient will register a client for a status page with the monitor. Each client
+// is identified by its name, which is attached to every notification for that page. Clients
+// that don't implement ghstatus.NamedClient are named after the Github Status page.
+func (m *Monitor) RegisterClient(client ghstatus.Client) error {
+	m.clientsMu.Lock()
+	defer m.clientsMu.Unlock()
+
+	name := ghstatus.PageName(client)
+	if _, ok := m.clients[name]; ok {
+		return fmt.Errorf("duplicate status page %s", name)
+	}
+
+	m.clients[name] = client
+	return nil
 }
 
 // RegisterNotifier will register a notifier with the monitor.
@@ -55,141 +184,356 @@ func (m *Monitor) RegisterNotifier(notifier notifier.Notifier) error {
 	return nil
 }
 
-// MonitorAndNotify will monitor the Github Status and notify subscribers upon relevant changes.
+// MonitorAndNotify will monitor every registered status page and notify subscribers upon relevant
+// changes. Each page is polled concurrently and keeps its own state. This blocks until the context
+// is done.
 func (m *Monitor) MonitorAndNotify(ctx context.Context, timeBetweenPolls time.Duration) {
-	ticker := m.clock.NewTicker(timeBetweenPolls)
-	defer ticker.Stop()
+	m.clientsMu.RLock()
+	clients := make([]ghstatus.Client, 0, len(m.clients))
+	for _, client := range m.clients {
+		clients = append(clients, client)
+	}
+	m.clientsMu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(client ghstatus.Client) {
+			defer wg.Done()
+			m.monitorPage(ctx, client, timeBetweenPolls)
+		}(client)
+	}
+	wg.Wait()
+}
 
-	var lastSummary ghstatus.SummaryResponse
-	var err error
+// monitorPage will monitor a single status page until the context is done.
+func (m *Monitor) monitorPage(ctx context.Context, client ghstatus.Client, timeBetweenPolls time.Duration) {
+	p := newPage(m.log, client)
+	if m.stabilization.enabled() {
+		p.stabilizer = newStabilizer(m.stabilization)
+	}
+	if err := p.load(ctx, m.stateStore); err != nil {
+		p.log.With(zap.Error(err)).Error("error loading state, starting fresh")
+	}
 
+	var interval time.Duration
 	for {
-		lastSummary, err = m.detectChangesAndNotify(ctx, lastSummary)
-		if err != nil {
-			m.log.With(zap.Error(err)).Error("error during monitoring")
+		start := m.clock.Now()
+
+		if err := m.deliverDigests(ctx, p); err != nil {
+			p.log.With(zap.Error(err)).Error("error delivering digests")
+		}
+		if err := m.releaseHeld(ctx, p); err != nil {
+			p.log.With(zap.Error(err)).Error("error releasing held changes")
+		}
+		var retryAfter time.Duration
+		if err := m.detectChangesAndNotify(ctx, p); err != nil {
+			p.log.With(zap.Error(err)).Error("error during monitoring")
+			var rateLimitErr *ghstatus.RateLimitError
+			if errors.As(err, &rateLimitErr) {
+				retryAfter = rateLimitErr.RetryAfter
+			}
+		}
+		if err := m.escalate(ctx, p); err != nil {
+			p.log.With(zap.Error(err)).Error("error escalating incidents")
 		}
 
+		// Wait for the interval from the start of this poll rather than from now so that slow polls don't
+		// push every later poll back.
+		interval = m.polling.interval(p.lastSummary, timeBetweenPolls, interval)
+		wait := m.polling.jitter(interval) - m.clock.Since(start)
+		if wait < retryAfter {
+			// Don't poll again before the status API is willing to answer.
+			wait = retryAfter
+		}
+		p.log.With(zap.Duration("interval", interval)).Debug("Waiting for the next poll.")
+
+		timer := m.clock.NewTimer(wait)
 		select {
-		case <-ticker.Chan():
+		case <-timer.Chan():
 		case <-ctx.Done():
+			timer.Stop()
 			return
 		}
 	}
 }
 
 // detectChangesAndNotify will detect any changes and send notifications based on the differences.
-func (m *Monitor) detectChangesAndNotify(ctx context.Context, lastSummary ghstatus.SummaryResponse) (ghstatus.SummaryResponse, error) {
-	summary, err := m.client.Summary(ctx)
+func (m *Monitor) detectChangesAndNotify(ctx context.Context, p *page) error {
+	summary, err := p.client.Summary(ctx)
 	if err != nil {
-		return lastSummary, fmt.Errorf("error getting summary: %w", err)
+		return errors.Join(fmt.Errorf("error getting summary: %w", err), m.pollFailed(ctx, p, err))
+	}
+	if err := m.pollSucceeded(ctx, p); err != nil {
+		p.log.With(zap.Error(err)).Error("error reporting recovery")
 	}
 
+	lastSummary := p.lastSummary
+
 	// Skip the first notification if notifyOnFirstRun is disabled.
 	if lastSummary.Page.UpdatedAt.IsZero() && !m.notifyOnFirstRun {
-		m.log.Debug("Notify on first run is disabled, skipping the notification.")
-		return summary, nil
+		p.log.Debug("Notify on first run is disabled, skipping the notification.")
+		p.lastSummary = summary
+		p.markNotified(summary.Incidents, summary.ScheduledMaintenances)
+		p.addBaselineIncidents(summary.Incidents)
+		return p.save(ctx, m.stateStore)
 	}
 
-	// If the summary page hasn't updated, no need to continue.
-	if summary.Page.UpdatedAt.Equal(lastSummary.Page.UpdatedAt) {
-		m.log.Debug("Current summary is equal to the old one, no updates.")
-		return lastSummary, err
+	// If the summary page hasn't updated and no changes are being held back, no need to continue.
+	summaryUpdated := !summary.Page.UpdatedAt.Equal(lastSummary.Page.UpdatedAt)
+	if !summaryUpdated && (p.stabilizer == nil || !p.stabilizer.pending()) {
+		p.log.Debug("Current summary is equal to the old one, no updates.")
+		return nil
 	}
 
-	// Check to see if the status has changed.
-	var changedStatus *ghstatus.Status
-	if lastSummary.Status.Description != summary.Status.Description || lastSummary.Status.Indicator != summary.Status.Indicator {
-		changedStatus = &summary.Status
-	}
+	var errs []error
 
-	changedComponents := findChangedComponents(lastSummary.Components, summary.Components)
-	changedIncidents := findChangedIncidents(lastSummary.Incidents, summary.Incidents)
-	changedScheduledMaintenances := findChangedScheduledMaintenances(lastSummary.ScheduledMaintenances, summary.ScheduledMaintenances)
-
-	if changedStatus != nil || len(changedComponents) > 0 || len(changedIncidents) > 0 || len(changedScheduledMaintenances) > 0 {
-		m.log.Debug("A change was found, running through the notifiers.")
-		var errs []error
-		notifierMsg := notifier.Message{
-			ChangedStatus:                changedStatus,
-			ChangedComponents:            changedComponents,
-			ChangedIncidents:             changedIncidents,
-			ChangedScheduledMaintenances: changedScheduledMaintenances,
+	changes := changeSet{groupNames: findGroupNames(summary.Components)}
+	if summaryUpdated {
+		changes = findChanges(lastSummary, summary)
+		if err := m.confirmRemovals(ctx, p, &changes); err != nil {
+			errs = append(errs, err)
 		}
-		for _, notifier := range m.notifiers {
-			if err := notifier.Notify(ctx, notifierMsg); err != nil {
+		changes.incidents = unnotified(p, changes.incidents, getIncidentUpdates)
+		changes.scheduledMaintenances = unnotified(p, changes.scheduledMaintenances, getScheduledMaintenanceUpdates)
+
+		p.lastSummary = summary
+		p.markNotified(currentResources(changes.incidents), currentResources(changes.scheduledMaintenances))
+
+		if !changes.empty() {
+			if err := m.journalChanges(p.name, changes); err != nil {
 				errs = append(errs, err)
 			}
 		}
-		return summary, errors.Join(errs...)
 	}
 
-	return summary, nil
-}
+	if p.stabilizer != nil {
+		p.stabilizer.stabilize(m.clock.Now(), &changes)
+	}
+
+	if !changes.empty() {
+		p.log.Debug("A change was found, running through the notifiers.")
+
+		msg := changes.message(p.name)
+		if m.filter != nil {
+			msg = msg.Filter(m.filter.Matches)
+		}
+		if !msg.Empty() {
+			errs = append(errs, m.notify(ctx, p, msg))
+		} else {
+			p.log.Debug("All changes were filtered out, skipping the notification.")
+		}
+	}
+
+	if err := p.save(ctx, m.stateStore); err != nil {
+		errs = append(errs, err)
+	}
 
-// these functions will be used for finding changed resources generically.
-func getComponentID(component ghstatus.Component) string           { return component.Name }
-func getComponentUpdatedAt(component ghstatus.Component) time.Time { return component.UpdatedAt }
-func getIncidentID(incident ghstatus.Incident) string              { return incident.ID }
-func getIncidentUpdatedAt(incident ghstatus.Incident) time.Time    { return incident.UpdatedAt }
-func getScheduledMaintenanceID(scheduledMaintenance ghstatus.ScheduledMaintenance) string {
-	return scheduledMaintenance.ID
+	return errors.Join(errs...)
 }
-func getScheduledMaintenanceUpdatedAt(scheduledMaintenance ghstatus.ScheduledMaintenance) time.Time {
-	return scheduledMaintenance.UpdatedAt
+
+// confirmRemovals looks up the final state of incidents and scheduled maintenances that have disappeared
+// from the summary so that their resolution or completion can be announced. Anything that can't be found
+// is left as removed.
+func (m *Monitor) confirmRemovals(ctx context.Context, p *page, changes *changeSet) error {
+	var errs []error
+
+	if len(changes.removedIncidents) > 0 {
+		resp, err := p.client.AllIncidents(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error confirming removed incidents: %w", err))
+		} else {
+			var confirmed []resourceChange[ghstatus.Incident]
+			confirmed, changes.removedIncidents = confirmRemovedResources(
+				changes.removedIncidents, resp.Incidents, getIncidentID, getIncidentFields, getIncidentFinished)
+			changes.incidents = append(changes.incidents, confirmed...)
+		}
+	}
+
+	if len(changes.removedScheduledMaintenances) > 0 {
+		resp, err := p.client.AllScheduledMaintenances(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error confirming removed scheduled maintenances: %w", err))
+		} else {
+			var confirmed []resourceChange[ghstatus.ScheduledMaintenance]
+			confirmed, changes.removedScheduledMaintenances = confirmRemovedResources(
+				changes.removedScheduledMaintenances, resp.ScheduledMaintenances, getScheduledMaintenanceID, getScheduledMaintenanceFields,
+				getScheduledMaintenanceFinished)
+			changes.scheduledMaintenances = append(changes.scheduledMaintenances, confirmed...)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
-// idGetter will get the ID from a resource.
-type idGetter[T any] func(T) string
+// journalChanges writes the detected changes to the journal, if there is one.
+func (m *Monitor) journalChanges(page string, changes changeSet) error {
+	if m.journal == nil {
+		return nil
+	}
 
-// updatedAtGetter will get the updated at time from a resource.
-type updatedAtGetter[T any] func(T) time.Time
+	entries, err := journalEntries(m.clock.Now().UTC(), page, changes)
+	if err != nil {
+		return fmt.Errorf("error creating journal entries: %w", err)
+	}
 
-// findChangedComponents will return any components which have changed from the last known state.
-func findChangedComponents(last []ghstatus.Component, current []ghstatus.Component) []ghstatus.Component {
-	return findChangedResources(last, current, getComponentID, getComponentUpdatedAt)
+	if err := m.journal.Append(entries...); err != nil {
+		return fmt.Errorf("error writing to journal: %w", err)
+	}
+	return nil
 }
 
-// findChangedIncidents will return any incidents which have changed from the last known state.
-func findChangedIncidents(last []ghstatus.Incident, current []ghstatus.Incident) []ghstatus.Incident {
-	return findChangedResources(last, current, getIncidentID, getIncidentUpdatedAt)
+// notify sends the message to the registered notifiers. If there's a router, each notifier only receives
+// the changes routed to it.
+func (m *Monitor) notify(ctx context.Context, p *page, msg notifier.Message) error {
+	p.notifyMu.Lock()
+	defer p.notifyMu.Unlock()
+
+	notifiers := m.registeredNotifiers()
+	return m.deliver(ctx, p, notifiers, m.route(msg, notifiers))
 }
 
-// findChangedScheduledMaintenances will return any scheduled maintenances which have changed from the last known state.
-func findChangedScheduledMaintenances(last []ghstatus.ScheduledMaintenance, current []ghstatus.ScheduledMaintenance) []ghstatus.ScheduledMaintenance {
-	return findChangedResources(last, current, getScheduledMaintenanceID, getScheduledMaintenanceUpdatedAt)
+// deliver sends each message to the notifier it's keyed by, concurrently. Changes are held back from notifiers that are
+// in quiet hours or muted, and collected into the digest of notifiers in digest mode. This must be called
+// while holding the page's notifyMu.
+func (m *Monitor) deliver(ctx context.Context, p *page, notifiers map[string]notifier.Notifier,
+	messages map[string]notifier.Message) error {
+	now := m.clock.Now()
+	var sends []send
+	var errs []error
+	for name, msg := range messages {
+		notifier, ok := notifiers[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("changes routed to unknown notifier %s", name))
+			continue
+		}
+		msg = p.hold(name, msg, m.holds(name, now))
+		if msg.Empty() {
+			p.log.With(zap.String("notifier", name)).Debug("All changes were held back, skipping the notification.")
+			continue
+		}
+		if interval := m.digestInterval(name); interval > 0 {
+			p.collect(name, msg, now, interval)
+			p.log.With(zap.String("notifier", name)).Debug("Changes collected into the digest.")
+			continue
+		}
+		sends = append(sends, send{notifier: notifier, msg: msg})
+	}
+	errs = append(errs, m.fanOut(ctx, p, sends))
+	return errors.Join(errs...)
+}
+
+// holds returns a function that reports whether a change should be held back from the notifier at the given time.
+func (m *Monitor) holds(name string, now time.Time) func(notifier.Change) bool {
+	return func(change notifier.Change) bool {
+		return m.quiet != nil && m.quiet.Holds(name, now, change)
+	}
 }
 
-// findChangedResources will return any resources which have changed from the last known state.
-func findChangedResources[T any](last []T, current []T, idGetter idGetter[T], updatedAtGetter updatedAtGetter[T]) []T {
-	lastMap := map[string]T{}
-	for _, resource := range last {
-		lastMap[idGetter(resource)] = resource
+// releaseHeld delivers the changes held back from notifiers whose quiet hours or mute windows have ended
+// as a single summary per notifier. Changes that have been resolved in the meantime are dropped.
+func (m *Monitor) releaseHeld(ctx context.Context, p *page) error {
+	if len(p.held) == 0 {
+		return nil
 	}
 
-	var changedResources []T
-	// Compare the current list of resources to see if any of them are updated.
-	// We'll intentionally disregard any disappearing resources as it likely means the
-	// resource has been cleaned up or is resolved.
-	for _, resource := range current {
-		resourceID := idGetter(resource)
+	p.notifyMu.Lock()
+	defer p.notifyMu.Unlock()
+	notifiers := m.registeredNotifiers()
+
+	now := m.clock.Now()
+	released := false
+	var sends []send
+	var errs []error
+	for name, held := range p.held {
+		if m.quiet != nil && m.quiet.Active(name, now) {
+			continue
+		}
+		delete(p.held, name)
+		released = true
 
-		// Disregard the faux component if that's what we're looking at.
-		if resourceID == fauxComponentName {
+		log := p.log.With(zap.String("notifier", name))
+		msg := settle(held)
+		if msg.Empty() {
+			log.Debug("All held changes were resolved, dropping them.")
 			continue
 		}
+		msg.Deferred = true
 
-		// If this resource isn't present in the last map, then this is new.
-		lastResource, ok := lastMap[resourceID]
+		notifier, ok := notifiers[name]
 		if !ok {
-			changedResources = append(changedResources, resource)
+			errs = append(errs, fmt.Errorf("changes held for unknown notifier %s", name))
 			continue
 		}
+		log.Debug("Quiet window ended, delivering held changes.")
+		sends = append(sends, send{notifier: notifier, msg: msg})
+	}
+	errs = append(errs, m.fanOut(ctx, p, sends))
+
+	if released {
+		if err := p.save(ctx, m.stateStore); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliverDigests delivers the digests that are due.
+func (m *Monitor) deliverDigests(ctx context.Context, p *page) error {
+	if len(p.digests) == 0 {
+		return nil
+	}
 
-		// If the updated field has changed, then the resource has changed.
-		if !updatedAtGetter(lastResource).Equal(updatedAtGetter(resource)) {
-			changedResources = append(changedResources, resource)
+	p.notifyMu.Lock()
+	defer p.notifyMu.Unlock()
+	notifiers := m.registeredNotifiers()
+
+	due := p.dueDigests(m.clock.Now())
+	if len(due) == 0 {
+		return nil
+	}
+
+	var sends []send
+	var errs []error
+	for name, msg := range due {
+		notifier, ok := notifiers[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("digest collected for unknown notifier %s", name))
+			continue
 		}
+		p.log.With(zap.String("notifier", name)).Debug("Delivering digest.")
+		sends = append(sends, send{notifier: notifier, msg: msg})
+	}
+	errs = append(errs, m.fanOut(ctx, p, sends))
+
+	if err := p.save(ctx, m.stateStore); err != nil {
+		errs = append(errs, err)
 	}
+	return errors.Join(errs...)
+}
 
-	return changedResources
+// registeredNotifiers returns a copy of the registered notifiers, keyed by name, so that they can be notified
+// without holding notifiersMu.
+func (m *Monitor) registeredNotifiers() map[string]notifier.Notifier {
+	m.notifiersMu.RLock()
+	defer m.notifiersMu.RUnlock()
+
+	notifiers := make(map[string]notifier.Notifier, len(m.notifiers))
+	for name, notifier := range m.notifiers {
+		notifiers[name] = notifier
+	}
+	return notifiers
+}
+
+// route returns the message to send to each of the given notifiers, keyed by the notifier's name.
+func (m *Monitor) route(msg notifier.Message, notifiers map[string]notifier.Notifier) map[string]notifier.Message {
+	if m.router != nil {
+		return m.router.Route(msg)
+	}
+
+	messages := make(map[string]notifier.Message, len(notifiers))
+	for name := range notifiers {
+		messages[name] = msg
+	}
+	return messages
 }
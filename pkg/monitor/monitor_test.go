@@ -2,12 +2,15 @@ package monitor
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/jonboulle/clockwork"
 	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/journal"
 	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/mdwn/ghstatus/pkg/state"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -37,7 +40,8 @@ func TestMonitor(t *testing.T) {
 	clock := clockwork.NewFakeClock()
 
 	server, client := ghstatus.NewTestServerAndClient(t)
-	m := New(log, clock, client, true)
+	m := New(log, clock, true)
+	require.NoError(t, m.RegisterClient(client))
 
 	// Make this channel for the dummy notifier.
 	ch := make(chan notifier.Message, 1)
@@ -66,10 +70,25 @@ func TestMonitor(t *testing.T) {
 
 	msg := waitForNotification(t, ch)
 
-	require.Equal(t, notifier.Message{ChangedStatus: &status}, msg)
+	require.Equal(t, notifier.Message{
+		Page:          ghstatus.GithubPageName,
+		ChangedStatus: &status,
+		Changes: []notifier.Change{
+			{
+				Kind:     notifier.KindStatus,
+				Type:     notifier.Added,
+				Severity: ghstatus.SeverityMajor,
+				Fields: []notifier.FieldChange{
+					{Field: notifier.FieldIndicator, Current: "major"},
+					{Field: notifier.FieldDescription, Current: "something happened"},
+				},
+			},
+		},
+	}, msg)
 
 	// Let's add in a component.
 	component := ghstatus.Component{
+		ID:        "component",
 		Name:      "component",
 		UpdatedAt: clock.Now().UTC(),
 	}
@@ -85,10 +104,23 @@ func TestMonitor(t *testing.T) {
 
 	msg = waitForNotification(t, ch)
 
-	require.Equal(t, notifier.Message{ChangedComponents: []ghstatus.Component{component}}, msg)
+	require.Equal(t, notifier.Message{
+		Page:              ghstatus.GithubPageName,
+		ChangedComponents: []ghstatus.Component{component},
+		Changes: []notifier.Change{
+			{
+				Kind:   notifier.KindComponent,
+				Type:   notifier.Added,
+				ID:     "component",
+				Name:   "component",
+				Fields: []notifier.FieldChange{{Field: notifier.FieldName, Current: "component"}},
+			},
+		},
+	}, msg)
 
 	// Let's update the component.
 	component = ghstatus.Component{
+		ID:        "component",
 		Name:      "component",
 		UpdatedAt: clock.Now().UTC(),
 		Status:    ghstatus.DegradedPerformance,
@@ -105,7 +137,20 @@ func TestMonitor(t *testing.T) {
 
 	msg = waitForNotification(t, ch)
 
-	require.Equal(t, notifier.Message{ChangedComponents: []ghstatus.Component{component}}, msg)
+	require.Equal(t, notifier.Message{
+		Page:              ghstatus.GithubPageName,
+		ChangedComponents: []ghstatus.Component{component},
+		Changes: []notifier.Change{
+			{
+				Kind:     notifier.KindComponent,
+				Type:     notifier.Updated,
+				ID:       "component",
+				Name:     "component",
+				Severity: ghstatus.SeverityMinor,
+				Fields:   []notifier.FieldChange{{Field: notifier.FieldStatus, Current: "degraded_performance"}},
+			},
+		},
+	}, msg)
 
 	// Let's keep the everything the same and update with a new incident.
 	incident1 := ghstatus.Incident{
@@ -133,7 +178,11 @@ func TestMonitor(t *testing.T) {
 	msg = waitForNotification(t, ch)
 
 	require.Equal(t, notifier.Message{
+		Page:             ghstatus.GithubPageName,
 		ChangedIncidents: []ghstatus.Incident{incident1},
+		Changes: []notifier.Change{
+			{Kind: notifier.KindIncident, Type: notifier.Added, ID: "Incident 1"},
+		},
 	}, msg)
 
 	// Let's add another new incident
@@ -163,7 +212,11 @@ func TestMonitor(t *testing.T) {
 	msg = waitForNotification(t, ch)
 
 	require.Equal(t, notifier.Message{
+		Page:             ghstatus.GithubPageName,
 		ChangedIncidents: []ghstatus.Incident{incident2},
+		Changes: []notifier.Change{
+			{Kind: notifier.KindIncident, Type: notifier.Added, ID: "Incident 2"},
+		},
 	}, msg)
 
 	// Let's add in a scheduled maintenance
@@ -190,12 +243,358 @@ func TestMonitor(t *testing.T) {
 	msg = waitForNotification(t, ch)
 
 	require.Equal(t, notifier.Message{
+		Page: ghstatus.GithubPageName,
 		ChangedScheduledMaintenances: []ghstatus.ScheduledMaintenance{
 			maintenance,
 		},
+		Changes: []notifier.Change{
+			{
+				Kind:     notifier.KindScheduledMaintenance,
+				Type:     notifier.Added,
+				Name:     "Maintenance",
+				Severity: ghstatus.SeverityNone,
+				Fields:   []notifier.FieldChange{{Field: notifier.FieldName, Current: "Maintenance"}},
+			},
+		},
 	}, msg)
 }
 
+func TestMonitorMultiplePages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := zap.NewNop()
+	clock := clockwork.NewFakeClock()
+
+	githubServer, githubClient := ghstatus.NewTestServerAndClient(t)
+	npmServer, npmClient := ghstatus.NewTestServerAndClient(t, ghstatus.WithPageName("npm"))
+	m := New(log, clock, true)
+	require.NoError(t, m.RegisterClient(githubClient))
+	require.NoError(t, m.RegisterClient(npmClient))
+	require.Error(t, m.RegisterClient(npmClient))
+
+	ch := make(chan notifier.Message, 2)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{
+		ch: ch,
+	}))
+
+	githubServer.SetSummary(t, ghstatus.SummaryResponse{})
+	npmServer.SetSummary(t, ghstatus.SummaryResponse{})
+
+	go m.MonitorAndNotify(ctx, time.Minute)
+
+	// Wait for both pages to start their tickers.
+	clock.BlockUntil(2)
+
+	status := ghstatus.Status{
+		Indicator:   ghstatus.Minor,
+		Description: "registry degraded",
+	}
+	npmServer.SetSummary(t, ghstatus.SummaryResponse{
+		Page: ghstatus.Page{
+			UpdatedAt: clock.Now().UTC(),
+		},
+		Status: status,
+	})
+	clock.Advance(time.Minute)
+
+	msg := waitForNotification(t, ch)
+	require.Equal(t, "npm", msg.Page)
+	require.Equal(t, &status, msg.ChangedStatus)
+
+	// The Github page hasn't changed, so nothing else should have been sent.
+	select {
+	case msg := <-ch:
+		require.Failf(t, "unexpected notification", "%+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMonitorResumesFromState(t *testing.T) {
+	log := zap.NewNop()
+	clock := clockwork.NewFakeClock()
+	store := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+
+	server, client := ghstatus.NewTestServerAndClient(t)
+
+	incident := ghstatus.Incident{
+		ID:        "Incident 1",
+		UpdatedAt: clock.Now().UTC(),
+		IncidentUpdates: []ghstatus.IncidentUpdate{
+			{ID: "Update 1", Body: "Investigating"},
+		},
+	}
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:      ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Incidents: []ghstatus.Incident{incident},
+	})
+
+	// The first monitor should record the state without notifying.
+	ctx, cancel := context.WithCancel(context.Background())
+	m := New(log, clock, false, WithStateStore(store))
+	require.NoError(t, m.RegisterClient(client))
+	go m.MonitorAndNotify(ctx, time.Minute)
+
+	require.Eventually(t, func() bool {
+		_, ok, err := store.Load(ctx, ghstatus.PageName(client))
+		return err == nil && ok
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	// The incident is updated while the monitor is down.
+	clock.Advance(time.Minute)
+	incident.UpdatedAt = clock.Now().UTC()
+	incident.IncidentUpdates = append([]ghstatus.IncidentUpdate{{ID: "Update 2", Body: "Identified"}}, incident.IncidentUpdates...)
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:      ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Incidents: []ghstatus.Incident{incident},
+	})
+
+	// The restarted monitor shouldn't treat this as a first run and should notify about the update.
+	ctx, cancel = context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	m = New(log, clock, false, WithStateStore(store))
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{
+		ch: ch,
+	}))
+	go m.MonitorAndNotify(ctx, time.Minute)
+
+	msg := waitForNotification(t, ch)
+	require.Equal(t, notifier.Message{
+		Page:             ghstatus.GithubPageName,
+		ChangedIncidents: []ghstatus.Incident{incident},
+		Changes: []notifier.Change{
+			{Kind: notifier.KindIncident, Type: notifier.Updated, ID: "Incident 1"},
+		},
+	}, msg)
+
+	require.Eventually(t, func() bool {
+		pageState, _, err := store.Load(ctx, ghstatus.PageName(client))
+		return err == nil && len(pageState.NotifiedUpdateIDs) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestMonitorJournal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := zap.NewNop()
+	clock := clockwork.NewFakeClock()
+	journalPath := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := journal.Open(journalPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, j.Close()) })
+
+	server, client := ghstatus.NewTestServerAndClient(t)
+	m := New(log, clock, true, WithJournal(j))
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{
+		ch: ch,
+	}))
+
+	component := ghstatus.Component{
+		ID:        "actions",
+		Name:      "Actions",
+		Status:    ghstatus.Operational,
+		UpdatedAt: clock.Now().UTC(),
+	}
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:       ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Components: []ghstatus.Component{component},
+	})
+
+	go m.MonitorAndNotify(ctx, time.Minute)
+	waitForNotification(t, ch)
+
+	component.Status = ghstatus.MajorOutage
+	component.UpdatedAt = clock.Now().Add(time.Minute).UTC()
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:       ghstatus.Page{UpdatedAt: component.UpdatedAt},
+		Components: []ghstatus.Component{component},
+	})
+	clock.Advance(time.Minute)
+	waitForNotification(t, ch)
+
+	entries, err := journal.Read(journalPath, journal.Query{Component: "actions"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, ghstatus.GithubPageName, entries[1].Page)
+	require.Equal(t, journal.KindComponent, entries[1].Kind)
+	require.Equal(t, "new status: operational", entries[0].Summary())
+	require.Equal(t, "status: operational → major_outage", entries[1].Summary())
+
+	entries, err = journal.Read(journalPath, journal.Query{Since: clock.Now().Add(-time.Second), Kinds: []journal.Kind{journal.KindComponent}})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestMonitorConfirmsRemovedResources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := zap.NewNop()
+	clock := clockwork.NewFakeClock()
+
+	server, client := ghstatus.NewTestServerAndClient(t)
+	m := New(log, clock, true)
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{
+		ch: ch,
+	}))
+
+	incident := ghstatus.Incident{
+		ID:        "incident",
+		Name:      "Actions outage",
+		Status:    ghstatus.Monitoring,
+		UpdatedAt: clock.Now().UTC(),
+	}
+	maintenance := ghstatus.ScheduledMaintenance{
+		ID:        "maintenance",
+		Name:      "Database upgrade",
+		Status:    ghstatus.InProgress,
+		UpdatedAt: clock.Now().UTC(),
+	}
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:                  ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Incidents:             []ghstatus.Incident{incident},
+		ScheduledMaintenances: []ghstatus.ScheduledMaintenance{maintenance},
+	})
+
+	go m.MonitorAndNotify(ctx, time.Minute)
+	waitForNotification(t, ch)
+
+	// Both disappear from the summary, but only the incident can be found afterwards.
+	resolved := incident
+	resolved.Status = ghstatus.Resolved
+	resolved.UpdatedAt = clock.Now().Add(time.Minute).UTC()
+	server.SetAllIncidents(t, ghstatus.IncidentsResponse{Incidents: []ghstatus.Incident{resolved}})
+	server.SetAllScheduledMaintenances(t, ghstatus.ScheduledMaintenancesResponse{})
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page: ghstatus.Page{UpdatedAt: clock.Now().Add(time.Minute).UTC()},
+	})
+	clock.Advance(time.Minute)
+
+	msg := waitForNotification(t, ch)
+	require.Equal(t, notifier.Message{
+		Page:             ghstatus.GithubPageName,
+		ChangedIncidents: []ghstatus.Incident{resolved},
+		Changes: []notifier.Change{
+			{
+				Kind:     notifier.KindIncident,
+				Type:     notifier.Resolved,
+				ID:       "incident",
+				Name:     "Actions outage",
+				Severity: ghstatus.SeverityNone,
+				Fields: []notifier.FieldChange{
+					{Field: notifier.FieldStatus, Previous: "monitoring", Current: "resolved"},
+				},
+			},
+			{
+				Kind:     notifier.KindScheduledMaintenance,
+				Type:     notifier.Removed,
+				ID:       "maintenance",
+				Name:     "Database upgrade",
+				Severity: ghstatus.SeverityMaintenance,
+			},
+		},
+	}, msg)
+}
+
+func TestMonitorIgnoresRemovedResourcesThatDidntChange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := zap.NewNop()
+	clock := clockwork.NewFakeClock()
+
+	server, client := ghstatus.NewTestServerAndClient(t)
+	m := New(log, clock, true)
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{
+		ch: ch,
+	}))
+
+	resolved := ghstatus.Incident{
+		ID:        "incident",
+		Name:      "Actions outage",
+		Status:    ghstatus.Resolved,
+		UpdatedAt: clock.Now().UTC(),
+	}
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:      ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Incidents: []ghstatus.Incident{resolved},
+	})
+
+	go m.MonitorAndNotify(ctx, time.Minute)
+	waitForNotification(t, ch)
+
+	// The incident was already resolved when it disappeared from the summary, so only the status is announced.
+	server.SetAllIncidents(t, ghstatus.IncidentsResponse{Incidents: []ghstatus.Incident{resolved}})
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:   ghstatus.Page{UpdatedAt: clock.Now().Add(time.Minute).UTC()},
+		Status: ghstatus.Status{Indicator: ghstatus.Minor, Description: "Minor Service Outage"},
+	})
+	clock.Advance(time.Minute)
+
+	msg := waitForNotification(t, ch)
+	require.Empty(t, msg.ChangedIncidents)
+	require.Len(t, msg.Changes, 1)
+	require.Equal(t, notifier.KindStatus, msg.Changes[0].Kind)
+}
+
+func TestMonitorReportsUnresolvedResourcesThatDisappear(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	log := zap.NewNop()
+	clock := clockwork.NewFakeClock()
+
+	server, client := ghstatus.NewTestServerAndClient(t)
+	m := New(log, clock, true)
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{
+		ch: ch,
+	}))
+
+	incident := ghstatus.Incident{
+		ID:        "incident",
+		Name:      "Actions outage",
+		Status:    ghstatus.Investigating,
+		UpdatedAt: clock.Now().UTC(),
+	}
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:      ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Incidents: []ghstatus.Incident{incident},
+	})
+
+	go m.MonitorAndNotify(ctx, time.Minute)
+	waitForNotification(t, ch)
+
+	// The incident disappears while still unresolved, and is unchanged in the list of all incidents.
+	server.SetAllIncidents(t, ghstatus.IncidentsResponse{Incidents: []ghstatus.Incident{incident}})
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page: ghstatus.Page{UpdatedAt: clock.Now().Add(time.Minute).UTC()},
+	})
+	clock.Advance(time.Minute)
+
+	msg := waitForNotification(t, ch)
+	require.Equal(t, []notifier.Change{
+		{
+			Kind:     notifier.KindIncident,
+			Type:     notifier.Removed,
+			ID:       "incident",
+			Name:     "Actions outage",
+			Severity: incident.Severity(),
+		},
+	}, msg.Changes)
+}
+
 func waitForNotification(t *testing.T, ch chan notifier.Message) notifier.Message {
 	select {
 	case msg := <-ch:
@@ -0,0 +1,196 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/mdwn/ghstatus/pkg/state"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxNotifiedUpdateIDs is the number of notified update IDs to remember per page.
+	maxNotifiedUpdateIDs = 1000
+)
+
+// page is a monitored status page along with the state the monitor keeps for it.
+type page struct {
+	log    *zap.Logger
+	name   string
+	client ghstatus.Client
+
+	// notifyMu serializes the notifications for the page so that notifiers receive its changes in order.
+	notifyMu sync.Mutex
+
+	lastSummary       ghstatus.SummaryResponse
+	notifiedUpdateIDs []string
+
+	// stabilizer holds back component status changes. This is nil if stabilization is disabled.
+	stabilizer *stabilizer
+
+	// held are the changes held back during quiet hours or a mute window, keyed by notifier name.
+	held map[string]notifier.Message
+
+	// digests are the changes collected for notifiers in digest mode, keyed by notifier name.
+	digests map[string]state.Digest
+
+	// escalations track the unresolved incidents for escalation policies, keyed by incident ID.
+	escalations map[string]state.IncidentEscalation
+
+	// baselineIncidents are the IDs of the incidents that were already listed when the page started being
+	// monitored, either in the loaded state or on a first poll that wasn't notified. Escalations that are
+	// already due for them when they're first tracked aren't sent.
+	baselineIncidents map[string]struct{}
+
+	// failures is the number of polls in a row that have failed, since failingSince.
+	failures     int
+	failingSince time.Time
+
+	// unavailableSince is when the page started failing to be polled if it has been reported as unavailable.
+	unavailableSince time.Time
+}
+
+// newPage creates a new page for the given client.
+func newPage(log *zap.Logger, client ghstatus.Client) *page {
+	name := ghstatus.PageName(client)
+	return &page{
+		log:         log.With(zap.String("page", name)),
+		name:        name,
+		client:      client,
+		held:        map[string]notifier.Message{},
+		digests:     map[string]state.Digest{},
+		escalations: map[string]state.IncidentEscalation{},
+	}
+}
+
+// load will load the page state from the store, if there is one.
+func (p *page) load(ctx context.Context, store state.Store) error {
+	if store == nil {
+		return nil
+	}
+
+	pageState, ok, err := store.Load(ctx, p.name)
+	if err != nil {
+		return fmt.Errorf("error loading state: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	p.lastSummary = pageState.LastSummary
+	p.addBaselineIncidents(pageState.LastSummary.Incidents)
+	p.notifiedUpdateIDs = pageState.NotifiedUpdateIDs
+	for name, msg := range pageState.HeldMessages {
+		p.held[name] = msg
+	}
+	for name, digest := range pageState.Digests {
+		p.digests[name] = digest
+	}
+	for id, escalation := range pageState.Escalations {
+		p.escalations[id] = escalation
+	}
+	p.unavailableSince = pageState.UnavailableSince
+	p.log.Debug("Loaded previous state.")
+	return nil
+}
+
+// save will save the page state to the store, if there is one.
+func (p *page) save(ctx context.Context, store state.Store) error {
+	if store == nil {
+		return nil
+	}
+
+	if err := store.Save(ctx, p.name, state.PageState{
+		LastSummary:       p.lastSummary,
+		NotifiedUpdateIDs: p.notifiedUpdateIDs,
+		HeldMessages:      p.held,
+		Digests:           p.digests,
+		Escalations:       p.escalations,
+		UnavailableSince:  p.unavailableSince,
+	}); err != nil {
+		return fmt.Errorf("error saving state: %w", err)
+	}
+	return nil
+}
+
+// addBaselineIncidents records the incidents as listed before the page started being monitored.
+func (p *page) addBaselineIncidents(incidents []ghstatus.Incident) {
+	if p.baselineIncidents == nil {
+		p.baselineIncidents = map[string]struct{}{}
+	}
+	for _, incident := range incidents {
+		p.baselineIncidents[incident.ID] = struct{}{}
+	}
+}
+
+// hasNotified returns true if the given update has already been notified.
+func (p *page) hasNotified(updates []ghstatus.IncidentUpdate) bool {
+	id := latestUpdateID(updates)
+	if id == "" {
+		return false
+	}
+	for _, notifiedID := range p.notifiedUpdateIDs {
+		if notifiedID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// markNotified records the latest updates of the given incidents and scheduled maintenances as notified.
+func (p *page) markNotified(incidents []ghstatus.Incident, scheduledMaintenances []ghstatus.ScheduledMaintenance) {
+	for _, incident := range incidents {
+		p.addNotifiedUpdate(incident.IncidentUpdates)
+	}
+	for _, scheduledMaintenance := range scheduledMaintenances {
+		p.addNotifiedUpdate(scheduledMaintenance.IncidentUpdates)
+	}
+
+	if len(p.notifiedUpdateIDs) > maxNotifiedUpdateIDs {
+		p.notifiedUpdateIDs = p.notifiedUpdateIDs[len(p.notifiedUpdateIDs)-maxNotifiedUpdateIDs:]
+	}
+}
+
+// addNotifiedUpdate records the latest of the given updates as notified.
+func (p *page) addNotifiedUpdate(updates []ghstatus.IncidentUpdate) {
+	if id := latestUpdateID(updates); id != "" && !p.hasNotified(updates) {
+		p.notifiedUpdateIDs = append(p.notifiedUpdateIDs, id)
+	}
+}
+
+// updatesGetter will get the updates from a resource.
+type updatesGetter[T any] func(T) []ghstatus.IncidentUpdate
+
+func getIncidentUpdates(incident ghstatus.Incident) []ghstatus.IncidentUpdate {
+	return incident.IncidentUpdates
+}
+func getScheduledMaintenanceUpdates(scheduledMaintenance ghstatus.ScheduledMaintenance) []ghstatus.IncidentUpdate {
+	return scheduledMaintenance.IncidentUpdates
+}
+
+// unnotified filters out resources that weren't in the last summary but whose latest update has
+// already been notified. Resources that were in the last summary are left alone, as the diff
+// against the last summary already knows they've changed.
+func unnotified[T any](p *page, changes []resourceChange[T], updatesGetter updatesGetter[T]) []resourceChange[T] {
+	var unnotified []resourceChange[T]
+	for _, change := range changes {
+		if change.previous == nil && p.hasNotified(updatesGetter(change.current)) {
+			p.log.Debug("Update already notified, skipping.")
+			continue
+		}
+		unnotified = append(unnotified, change)
+	}
+	return unnotified
+}
+
+// latestUpdateID returns the ID of the latest update. The API returns updates newest first.
+func latestUpdateID(updates []ghstatus.IncidentUpdate) string {
+	if len(updates) == 0 {
+		return ""
+	}
+	return updates[0].ID
+}
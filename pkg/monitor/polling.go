@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+)
+
+// Polling adapts how often pages are polled to what's happening on them. Pages are otherwise polled at the
+// time between polls given to MonitorAndNotify.
+type Polling struct {
+	// ActiveInterval is the time between polls while the page's status indicator isn't none, a component
+	// isn't operational or an incident is unresolved. Zero polls at the usual interval.
+	ActiveInterval time.Duration
+
+	// MaxInterval is the longest time between polls while everything is operational. The time between polls
+	// doubles with every poll that finds everything operational, up to this. Zero doesn't back off.
+	MaxInterval time.Duration
+
+	// Jitter is the fraction of each interval that's randomly added or subtracted so that several monitors
+	// don't poll in lockstep, e.g. 0.1 for up to 10%.
+	Jitter float64
+}
+
+// interval returns the time to wait before the next poll, without jitter, given the summary of the last poll
+// and the interval that was waited before it.
+func (p Polling) interval(summary ghstatus.SummaryResponse, base time.Duration, last time.Duration) time.Duration {
+	if active(summary) {
+		if p.ActiveInterval > 0 {
+			return p.ActiveInterval
+		}
+		return base
+	}
+
+	if p.MaxInterval <= base || last < base {
+		return base
+	}
+	if next := 2 * last; next < p.MaxInterval {
+		return next
+	}
+	return p.MaxInterval
+}
+
+// jitter randomly varies the interval by up to the configured fraction.
+func (p Polling) jitter(interval time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration((2*rand.Float64()-1)*p.Jitter*float64(interval))
+}
+
+// active returns true if something is wrong on the page.
+func active(summary ghstatus.SummaryResponse) bool {
+	if indicator := summary.Status.Indicator; indicator != "" && indicator != ghstatus.None {
+		return true
+	}
+	for _, component := range summary.Components {
+		if component.Status != "" && component.Status != ghstatus.Operational {
+			return true
+		}
+	}
+	for _, incident := range summary.Incidents {
+		if !incident.Status.Resolved() {
+			return true
+		}
+	}
+	return false
+}
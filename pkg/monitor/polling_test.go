@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMonitorAdaptsPolling(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clock := clockwork.NewFakeClock()
+	server, testClient := ghstatus.NewTestServerAndClient(t)
+	client := &pollingClient{Client: testClient, polls: make(chan struct{}, 10)}
+
+	m := New(zap.NewNop(), clock, false, WithPolling(Polling{ActiveInterval: 10 * time.Second, MaxInterval: 4 * time.Minute}))
+	require.NoError(t, m.RegisterClient(client))
+
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:   ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Status: ghstatus.Status{Indicator: ghstatus.None},
+	})
+
+	go m.MonitorAndNotify(ctx, time.Minute)
+	<-client.polls
+
+	// Everything is operational, so the time between polls doubles.
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-client.polls
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case <-client.polls:
+		require.FailNow(t, "polled before the interval backed off")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:      ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Status:    ghstatus.Status{Indicator: ghstatus.Minor},
+		Incidents: []ghstatus.Incident{{ID: "incident", Status: ghstatus.Investigating, Impact: ghstatus.Minor}},
+	})
+	clock.Advance(time.Minute)
+	<-client.polls
+
+	// An incident is unresolved, so the page is polled at the active interval.
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	<-client.polls
+}
+
+func TestPollingInterval(t *testing.T) {
+	operational := ghstatus.SummaryResponse{Status: ghstatus.Status{Indicator: ghstatus.None}}
+	degraded := ghstatus.SummaryResponse{
+		Status:     ghstatus.Status{Indicator: ghstatus.None},
+		Components: []ghstatus.Component{{Status: ghstatus.PartialOutage}},
+	}
+	polling := Polling{ActiveInterval: 10 * time.Second, MaxInterval: 5 * time.Minute}
+
+	require.Equal(t, 2*time.Minute, polling.interval(operational, time.Minute, time.Minute))
+	require.Equal(t, 5*time.Minute, polling.interval(operational, time.Minute, 4*time.Minute))
+	require.Equal(t, 10*time.Second, polling.interval(degraded, time.Minute, 4*time.Minute))
+	// Backing off starts over after something has gone wrong.
+	require.Equal(t, time.Minute, polling.interval(operational, time.Minute, 10*time.Second))
+
+	require.Equal(t, time.Minute, Polling{}.interval(operational, time.Minute, time.Minute))
+	require.Equal(t, time.Minute, Polling{}.interval(degraded, time.Minute, time.Minute))
+}
+
+func TestPollingJitter(t *testing.T) {
+	require.Equal(t, time.Minute, Polling{}.jitter(time.Minute))
+
+	polling := Polling{Jitter: 0.1}
+	for i := 0; i < 100; i++ {
+		jittered := polling.jitter(time.Minute)
+		require.GreaterOrEqual(t, jittered, 54*time.Second)
+		require.LessOrEqual(t, jittered, 66*time.Second)
+	}
+}
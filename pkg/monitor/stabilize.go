@@ -0,0 +1,207 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+)
+
+// Stabilization configures how long component status changes must persist before they're reported,
+// and when a component is considered to be flapping.
+type Stabilization struct {
+	// Polls is the number of consecutive polls a component status change must be seen in before it's reported.
+	Polls int
+
+	// Duration is how long a component status change must persist before it's reported. If both Polls and
+	// Duration are set, the change is reported as soon as either is satisfied.
+	Duration time.Duration
+
+	// FlapThreshold is the number of status changes within the flap window after which a component is
+	// considered to be flapping. Zero disables flap detection.
+	FlapThreshold int
+
+	// FlapWindow is the window status changes are counted in for flap detection. A flapping component is
+	// considered stable again once its status hasn't changed for this long.
+	FlapWindow time.Duration
+}
+
+// enabled returns true if the stabilization does anything.
+func (s Stabilization) enabled() bool {
+	return s.Polls > 1 || s.Duration > 0 || s.FlapThreshold > 0
+}
+
+// stabilizer holds back component status changes until they've persisted, and collapses flapping
+// components into a single notification.
+type stabilizer struct {
+	config     Stabilization
+	components map[string]*componentStability
+}
+
+// componentStability is the stabilization state of a single component.
+type componentStability struct {
+	// reported is the component as it was last reported to the notifiers.
+	reported ghstatus.Component
+
+	// observed is the component as it was last seen.
+	observed ghstatus.Component
+
+	// pending is whether the observed status is yet to be reported, and pendingSince and pendingPolls
+	// are when it was first seen and how many polls it's been seen in since.
+	pending      bool
+	pendingSince time.Time
+	pendingPolls int
+
+	// transitions are the times of the status changes within the flap window.
+	transitions []time.Time
+
+	// unstable is whether the component is flapping.
+	unstable bool
+}
+
+// newStabilizer creates a new stabilizer.
+func newStabilizer(config Stabilization) *stabilizer {
+	return &stabilizer{
+		config:     config,
+		components: map[string]*componentStability{},
+	}
+}
+
+// stabilize replaces the component changes in the change set with those that are ready to be reported.
+// This is called on every poll, whether the summary has changed or not, so that pending changes can mature.
+func (s *stabilizer) stabilize(now time.Time, changes *changeSet) {
+	var report []resourceChange[ghstatus.Component]
+	for _, change := range changes.components {
+		if s.observe(now, change, changes) {
+			report = append(report, change)
+		}
+	}
+	changes.components = report
+
+	ids := make([]string, 0, len(s.components))
+	for id := range s.components {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		s.mature(now, s.components[id], changes)
+	}
+}
+
+// pending returns true if there are component changes that may still be reported.
+func (s *stabilizer) pending() bool {
+	for _, component := range s.components {
+		if component.pending || component.unstable {
+			return true
+		}
+	}
+	return false
+}
+
+// observe records a component change. It returns true if the change should be reported right away, which
+// is the case for new components and changes that don't affect the status.
+func (s *stabilizer) observe(now time.Time, change resourceChange[ghstatus.Component], changes *changeSet) bool {
+	if change.previous == nil {
+		s.components[change.current.ID] = &componentStability{reported: change.current, observed: change.current}
+		return true
+	}
+
+	component, ok := s.components[change.current.ID]
+	if !ok {
+		component = &componentStability{reported: *change.previous, observed: *change.previous}
+		s.components[change.current.ID] = component
+	}
+
+	statusChanged := component.observed.Status != change.current.Status
+	component.observed = change.current
+	if !statusChanged {
+		if component.pending || component.unstable {
+			return false
+		}
+		component.reported = change.current
+		return true
+	}
+
+	component.transitions = append(component.transitions, now)
+	s.pruneTransitions(now, component)
+
+	if component.unstable {
+		return false
+	}
+
+	if s.config.FlapThreshold > 0 && len(component.transitions) >= s.config.FlapThreshold {
+		component.unstable = true
+		component.pending = false
+		reported := component.reported
+		changes.unstableComponents = append(changes.unstableComponents, resourceChange[ghstatus.Component]{
+			previous: &reported,
+			current:  change.current,
+		})
+		return false
+	}
+
+	component.pending = change.current.Status != component.reported.Status
+	if component.pending {
+		component.pendingSince = now
+		component.pendingPolls = 0
+	}
+	return false
+}
+
+// mature reports the component's pending change if it has persisted long enough, or that the component
+// has stabilized if it was flapping and its status hasn't changed for the flap window.
+func (s *stabilizer) mature(now time.Time, component *componentStability, changes *changeSet) {
+	s.pruneTransitions(now, component)
+
+	if component.unstable {
+		if len(component.transitions) > 0 {
+			return
+		}
+		reported := component.reported
+		changes.stabilizedComponents = append(changes.stabilizedComponents, resourceChange[ghstatus.Component]{
+			previous: &reported,
+			current:  component.observed,
+		})
+		component.unstable = false
+		component.reported = component.observed
+		return
+	}
+
+	if !component.pending {
+		return
+	}
+
+	component.pendingPolls++
+	if !s.persisted(now, component) {
+		return
+	}
+
+	reported := component.reported
+	changes.components = append(changes.components, resourceChange[ghstatus.Component]{
+		previous: &reported,
+		current:  component.observed,
+	})
+	component.pending = false
+	component.reported = component.observed
+}
+
+// persisted returns true if the component's pending change has persisted long enough to be reported.
+func (s *stabilizer) persisted(now time.Time, component *componentStability) bool {
+	if s.config.Polls <= 1 && s.config.Duration <= 0 {
+		return true
+	}
+	pollsElapsed := s.config.Polls > 1 && component.pendingPolls >= s.config.Polls
+	durationElapsed := s.config.Duration > 0 && now.Sub(component.pendingSince) >= s.config.Duration
+	return pollsElapsed || durationElapsed
+}
+
+// pruneTransitions forgets status changes that have fallen out of the flap window.
+func (s *stabilizer) pruneTransitions(now time.Time, component *componentStability) {
+	var transitions []time.Time
+	for _, t := range component.transitions {
+		if now.Sub(t) < s.config.FlapWindow {
+			transitions = append(transitions, t)
+		}
+	}
+	component.transitions = transitions
+}
@@ -0,0 +1,159 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// pollingClient signals every time the summary has been polled.
+type pollingClient struct {
+	ghstatus.Client
+	polls chan struct{}
+}
+
+func (c *pollingClient) Summary(ctx context.Context) (ghstatus.SummaryResponse, error) {
+	defer func() { c.polls <- struct{}{} }()
+	return c.Client.Summary(ctx)
+}
+
+func TestMonitorStabilizesComponentChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clock := clockwork.NewFakeClock()
+	server, testClient := ghstatus.NewTestServerAndClient(t)
+	client := &pollingClient{Client: testClient, polls: make(chan struct{}, 10)}
+
+	m := New(zap.NewNop(), clock, true, WithStabilization(Stabilization{Polls: 3}))
+	require.NoError(t, m.RegisterClient(client))
+	ch := make(chan notifier.Message, 1)
+	require.NoError(t, m.RegisterNotifier(&channelNotifier{ch: ch}))
+
+	operational := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.Operational, UpdatedAt: clock.Now().UTC()}
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:       ghstatus.Page{UpdatedAt: clock.Now().UTC()},
+		Components: []ghstatus.Component{operational},
+	})
+
+	go m.MonitorAndNotify(ctx, time.Minute)
+	<-client.polls
+	waitForNotification(t, ch)
+
+	degraded := operational
+	degraded.Status = ghstatus.DegradedPerformance
+	degraded.UpdatedAt = clock.Now().Add(time.Minute).UTC()
+	server.SetSummary(t, ghstatus.SummaryResponse{
+		Page:       ghstatus.Page{UpdatedAt: clock.Now().Add(time.Minute).UTC()},
+		Components: []ghstatus.Component{degraded},
+	})
+
+	// The change is held back until it has been seen in three consecutive polls.
+	for i := 0; i < 2; i++ {
+		clock.Advance(time.Minute)
+		<-client.polls
+	}
+	select {
+	case msg := <-ch:
+		require.Failf(t, "unexpected notification", "%+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	clock.Advance(time.Minute)
+	msg := waitForNotification(t, ch)
+	require.Equal(t, []ghstatus.Component{degraded}, msg.ChangedComponents)
+	require.Equal(t, []notifier.Change{
+		{
+			Kind:     notifier.KindComponent,
+			Type:     notifier.Updated,
+			ID:       "actions",
+			Name:     "Actions",
+			Severity: ghstatus.SeverityMinor,
+			Fields: []notifier.FieldChange{
+				{Field: notifier.FieldStatus, Previous: "operational", Current: "degraded_performance"},
+			},
+		},
+	}, msg.Changes)
+}
+
+func TestStabilizerDropsReverts(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	s := newStabilizer(Stabilization{Duration: 5 * time.Minute})
+
+	operational := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.Operational}
+	degraded := operational
+	degraded.Status = ghstatus.DegradedPerformance
+
+	changes := changeSet{components: []resourceChange[ghstatus.Component]{{previous: &operational, current: degraded}}}
+	s.stabilize(clock.Now(), &changes)
+	require.True(t, changes.empty())
+	require.True(t, s.pending())
+
+	clock.Advance(2 * time.Minute)
+	changes = changeSet{components: []resourceChange[ghstatus.Component]{{previous: &degraded, current: operational}}}
+	s.stabilize(clock.Now(), &changes)
+	require.True(t, changes.empty())
+	require.False(t, s.pending())
+
+	clock.Advance(10 * time.Minute)
+	changes = changeSet{}
+	s.stabilize(clock.Now(), &changes)
+	require.True(t, changes.empty())
+}
+
+func TestStabilizerDetectsFlapping(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	s := newStabilizer(Stabilization{Polls: 2, FlapThreshold: 3, FlapWindow: 10 * time.Minute})
+
+	operational := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.Operational}
+	degraded := operational
+	degraded.Status = ghstatus.DegradedPerformance
+	outage := operational
+	outage.Status = ghstatus.PartialOutage
+
+	// Flip between statuses on every poll.
+	var changes changeSet
+	flips := []resourceChange[ghstatus.Component]{
+		{previous: &operational, current: degraded},
+		{previous: &degraded, current: operational},
+		{previous: &operational, current: degraded},
+	}
+	for _, flip := range flips {
+		changes = changeSet{components: []resourceChange[ghstatus.Component]{flip}}
+		s.stabilize(clock.Now(), &changes)
+		clock.Advance(time.Minute)
+	}
+
+	// The third flip marks the component as unstable, which is reported once.
+	require.Empty(t, changes.components)
+	require.Len(t, changes.unstableComponents, 1)
+	require.Equal(t, ghstatus.Operational, changes.unstableComponents[0].previous.Status)
+	require.Equal(t, ghstatus.DegradedPerformance, changes.unstableComponents[0].current.Status)
+	require.Equal(t, notifier.Unstable, changes.records()[0].Type)
+
+	// Further flips are suppressed.
+	changes = changeSet{components: []resourceChange[ghstatus.Component]{{previous: &degraded, current: outage}}}
+	s.stabilize(clock.Now(), &changes)
+	require.True(t, changes.empty())
+
+	// Once the status hasn't changed for the flap window, the component is reported as stabilized.
+	clock.Advance(9 * time.Minute)
+	changes = changeSet{}
+	s.stabilize(clock.Now(), &changes)
+	require.True(t, changes.empty())
+
+	clock.Advance(time.Minute)
+	changes = changeSet{}
+	s.stabilize(clock.Now(), &changes)
+	require.Len(t, changes.stabilizedComponents, 1)
+	require.Equal(t, ghstatus.Operational, changes.stabilizedComponents[0].previous.Status)
+	require.Equal(t, ghstatus.PartialOutage, changes.stabilizedComponents[0].current.Status)
+	require.Equal(t, notifier.Stabilized, changes.records()[0].Type)
+	require.False(t, s.pending())
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBusy is returned by Deliverer.Deliver for a notifier that's still running a call that timed out.
+var ErrBusy = errors.New("still running a call that timed out")
+
+// Deliverer sends messages to notifiers, keeping track of the calls that timed out but haven't returned yet.
+// A notifier that's stuck in a call isn't called again until that call returns, so that a hanging notifier
+// can't pile up goroutines. The zero value is ready to use.
+type Deliverer struct {
+	mu    sync.Mutex
+	stuck map[string]struct{}
+}
+
+// Deliver sends the message with the notifier, giving up once the timeout has passed. A panic in the notifier
+// is recovered and returned as an error. A notifier that doesn't return by the timeout is left to finish in
+// the background so that it can't hold up the caller, and later messages for it fail with ErrBusy until it
+// does. A timeout of zero or less doesn't time out.
+func (d *Deliverer) Deliver(ctx context.Context, n Notifier, msg Message, timeout time.Duration) error {
+	name := n.Name()
+
+	d.mu.Lock()
+	if _, ok := d.stuck[name]; ok {
+		d.mu.Unlock()
+		return fmt.Errorf("notifier %s: %w", name, ErrBusy)
+	}
+	d.mu.Unlock()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("notifier %s panicked: %v", name, r)
+			}
+		}()
+		result <- n.Notify(ctx, msg)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	select {
+	case err := <-result:
+		// The call returned while the timeout was being handled.
+		return err
+	default:
+	}
+	if d.stuck == nil {
+		d.stuck = map[string]struct{}{}
+	}
+	d.stuck[name] = struct{}{}
+	go func() {
+		<-result
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.stuck, name)
+	}()
+	return fmt.Errorf("notifier %s didn't finish and was left running: %w", name, ctx.Err())
+}
+
+// Stuck returns the number of notifier calls that timed out but haven't returned yet.
+func (d *Deliverer) Stuck() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.stuck)
+}
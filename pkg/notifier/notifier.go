@@ -2,6 +2,8 @@ package notifier
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/mdwn/ghstatus/pkg/ghstatus"
 )
@@ -11,24 +13,380 @@ type Notifier interface {
 	// Name is the name of the notifier.
 	Name() string
 
-	// Notify will notify an underlying system with the given message.
+	// Notify will notify an underlying system with the given message. The monitor may call this
+	// concurrently for messages from different pages.
 	Notify(context.Context, Message) error
 
 	// Cleanup performs any cleanup steps.
 	Cleanup() error
 }
 
+// Queuing is implemented by notifiers that queue messages to deliver them later. A nil error from their
+// Notify only means that the message was queued, not that it was delivered.
+type Queuing interface {
+	Notifier
+
+	// Queues returns true if messages are queued rather than delivered by Notify.
+	Queues() bool
+}
+
 // Message is a notification message.
 type Message struct {
+	// Page is the name of the status page the changes were observed on.
+	Page string `json:"page" yaml:"page"`
+
 	// ChangedStatus is populated if the status has changed.
-	ChangedStatus *ghstatus.Status
+	ChangedStatus *ghstatus.Status `json:"changed_status,omitempty" yaml:"changed_status,omitempty"`
 
 	// ChangedComponents is populated if the components have changed.
-	ChangedComponents []ghstatus.Component
+	ChangedComponents []ghstatus.Component `json:"changed_components,omitempty" yaml:"changed_components,omitempty"`
 
 	// ChangedIncidents is populated if the incidents have changed.
-	ChangedIncidents []ghstatus.Incident
+	ChangedIncidents []ghstatus.Incident `json:"changed_incidents,omitempty" yaml:"changed_incidents,omitempty"`
 
 	// ChangedScheduledMaintenances is populated of the scheduled maintenances have changed.
-	ChangedScheduledMaintenances []ghstatus.ScheduledMaintenance
+	ChangedScheduledMaintenances []ghstatus.ScheduledMaintenance `json:"changed_scheduled_maintenances,omitempty" yaml:"changed_scheduled_maintenances,omitempty"`
+
+	// Changes holds a structured record of every change in the message, including the previous
+	// and current values of each changed field.
+	Changes []Change `json:"changes,omitempty" yaml:"changes,omitempty"`
+
+	// Deferred is true if the changes were held back during quiet hours or a mute window and are
+	// being delivered as a summary now that it has ended.
+	Deferred bool `json:"deferred,omitempty" yaml:"deferred,omitempty"`
+}
+
+// Kind is the kind of resource a change is about.
+type Kind string
+
+const (
+	KindStatus               Kind = "status"
+	KindComponent            Kind = "component"
+	KindIncident             Kind = "incident"
+	KindScheduledMaintenance Kind = "scheduled_maintenance"
+
+	// KindSource is the status page itself as a source of updates, which changes when it can't be polled.
+	KindSource Kind = "source"
+)
+
+// Kinds are all of the kinds of resources.
+var Kinds = []Kind{KindStatus, KindComponent, KindIncident, KindScheduledMaintenance, KindSource}
+
+// kindAliases are shorter names accepted for kinds.
+var kindAliases = map[string]Kind{
+	"maintenance": KindScheduledMaintenance,
+}
+
+// KindFromString returns a Kind from a string descriptor. "maintenance" is accepted for scheduled maintenances.
+func KindFromString(kind string) (Kind, error) {
+	for _, k := range Kinds {
+		if string(k) == kind {
+			return k, nil
+		}
+	}
+	if k, ok := kindAliases[kind]; ok {
+		return k, nil
+	}
+
+	valid := make([]string, 0, len(Kinds))
+	for _, k := range Kinds {
+		valid = append(valid, string(k))
+	}
+	return "", fmt.Errorf("unrecognized kind %s (valid values are [%s, maintenance])", kind, strings.Join(valid, ", "))
+}
+
+// ChangeType is the type of change made to a resource.
+type ChangeType string
+
+const (
+	// Added designates a resource that wasn't present previously.
+	Added ChangeType = "added"
+
+	// Updated designates a resource that was present previously and has been updated.
+	Updated ChangeType = "updated"
+
+	// Resolved designates an incident that has been resolved.
+	Resolved ChangeType = "resolved"
+
+	// Completed designates a scheduled maintenance that has been completed.
+	Completed ChangeType = "completed"
+
+	// Removed designates a resource that is no longer listed and whose final state couldn't be confirmed.
+	Removed ChangeType = "removed"
+
+	// Unstable designates a component that is flapping between statuses. Further changes to it are held
+	// back until it stabilizes.
+	Unstable ChangeType = "unstable"
+
+	// Stabilized designates a component that was flapping and has settled on a status.
+	Stabilized ChangeType = "stabilized"
+
+	// Escalated designates an incident that is still unresolved after the delay of an escalation policy.
+	Escalated ChangeType = "escalated"
+
+	// Unavailable designates a status page that has failed to be polled a number of times in a row.
+	Unavailable ChangeType = "unavailable"
+
+	// Recovered designates a status page that can be polled again after being unavailable.
+	Recovered ChangeType = "recovered"
+)
+
+// Field names used in field changes.
+const (
+	FieldName           = "name"
+	FieldDescription    = "description"
+	FieldStatus         = "status"
+	FieldIndicator      = "indicator"
+	FieldImpact         = "impact"
+	FieldScheduledFor   = "scheduled_for"
+	FieldScheduledUntil = "scheduled_until"
+	FieldComponents     = "components"
+	FieldUnresolvedFor  = "unresolved_for"
+	FieldFailures       = "failures"
+	FieldError          = "error"
+	FieldUnavailableFor = "unavailable_for"
+)
+
+// FieldChange is the previous and current value of a single field of a resource.
+type FieldChange struct {
+	// Field is the name of the field.
+	Field string `json:"field" yaml:"field"`
+
+	// Previous is the previous value of the field. This is empty for added resources.
+	Previous string `json:"previous,omitempty" yaml:"previous,omitempty"`
+
+	// Current is the current value of the field.
+	Current string `json:"current" yaml:"current"`
+}
+
+// Change is a structured record of a change to a single resource.
+type Change struct {
+	// Kind is the kind of resource that changed.
+	Kind Kind `json:"kind" yaml:"kind"`
+
+	// Type is the type of change.
+	Type ChangeType `json:"type" yaml:"type"`
+
+	// ID is the identifier of the resource. This is empty for the overall status.
+	ID string `json:"id,omitempty" yaml:"id,omitempty"`
+
+	// Name is the name of the resource.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Group is the name of the group the resource belongs to. This is only set for components in a group.
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+
+	// Components are the names of the components affected by an incident or scheduled maintenance.
+	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
+
+	// Severity is the severity of the change. This is the most severe of the resource's previous and
+	// current state, so that recoveries are as severe as the outages they end.
+	Severity ghstatus.Severity `json:"severity" yaml:"severity"`
+
+	// Fields are the fields that changed. For added resources, this holds the initial values.
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// Transition describes a change from the previous value to the current one, e.g. "operational → major_outage".
+// Notifiers and the journal use it so that changes read the same everywhere.
+func Transition(previous string, current string) string {
+	return fmt.Sprintf("%s → %s", previous, current)
+}
+
+// Field returns the change to the field with the given name, if it changed.
+func (c Change) Field(name string) (FieldChange, bool) {
+	for _, field := range c.Fields {
+		if field.Field == name {
+			return field, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+// Renamed returns the previous and current name of the resource if it has been renamed.
+func (c Change) Renamed() (string, string, bool) {
+	if c.Type != Updated && c.Type != Resolved && c.Type != Completed {
+		return "", "", false
+	}
+	field, ok := c.Field(FieldName)
+	if !ok || field.Previous == "" {
+		return "", "", false
+	}
+	return field.Previous, field.Current, true
+}
+
+// Change returns the change to the resource of the given kind and ID, if there is one.
+func (m Message) Change(kind Kind, id string) (Change, bool) {
+	for _, change := range m.Changes {
+		if change.Kind == kind && change.ID == id {
+			return change, true
+		}
+	}
+	return Change{}, false
+}
+
+// Filter returns a copy of the message containing only the changes for which keep returns true. The
+// changed status and resources are trimmed to match.
+func (m Message) Filter(keep func(Change) bool) Message {
+	filtered := Message{Page: m.Page, Deferred: m.Deferred}
+
+	kept := map[Kind]map[string]struct{}{}
+	for _, change := range m.Changes {
+		if !keep(change) {
+			continue
+		}
+		filtered.Changes = append(filtered.Changes, change)
+		if kept[change.Kind] == nil {
+			kept[change.Kind] = map[string]struct{}{}
+		}
+		kept[change.Kind][change.ID] = struct{}{}
+	}
+
+	if _, ok := kept[KindStatus][""]; ok {
+		filtered.ChangedStatus = m.ChangedStatus
+	}
+	filtered.ChangedComponents = filterResources(m.ChangedComponents, kept[KindComponent],
+		func(c ghstatus.Component) string { return c.ID })
+	filtered.ChangedIncidents = filterResources(m.ChangedIncidents, kept[KindIncident],
+		func(i ghstatus.Incident) string { return i.ID })
+	filtered.ChangedScheduledMaintenances = filterResources(m.ChangedScheduledMaintenances, kept[KindScheduledMaintenance],
+		func(s ghstatus.ScheduledMaintenance) string { return s.ID })
+
+	return filtered
+}
+
+// Merge returns a message combining the message with a later one for the same page. Changes to the same
+// resource are combined into a single change spanning from the earlier previous values to the later
+// current values, and the later state of each resource is kept.
+func (m Message) Merge(later Message) Message {
+	merged := Message{
+		Page:          m.Page,
+		ChangedStatus: m.ChangedStatus,
+		Deferred:      m.Deferred || later.Deferred,
+	}
+	if merged.Page == "" {
+		merged.Page = later.Page
+	}
+	if later.ChangedStatus != nil {
+		merged.ChangedStatus = later.ChangedStatus
+	}
+
+	merged.ChangedComponents = mergeResources(m.ChangedComponents, later.ChangedComponents,
+		func(c ghstatus.Component) string { return c.ID })
+	merged.ChangedIncidents = mergeResources(m.ChangedIncidents, later.ChangedIncidents,
+		func(i ghstatus.Incident) string { return i.ID })
+	merged.ChangedScheduledMaintenances = mergeResources(m.ChangedScheduledMaintenances, later.ChangedScheduledMaintenances,
+		func(s ghstatus.ScheduledMaintenance) string { return s.ID })
+
+	merged.Changes = append([]Change(nil), m.Changes...)
+	reverted := map[Kind]map[string]struct{}{}
+	for _, change := range later.Changes {
+		i := indexOfChange(merged.Changes, change.Kind, change.ID)
+		if i < 0 {
+			merged.Changes = append(merged.Changes, change)
+			continue
+		}
+		var ok bool
+		if merged.Changes[i], ok = merged.Changes[i].merge(change); !ok {
+			if reverted[change.Kind] == nil {
+				reverted[change.Kind] = map[string]struct{}{}
+			}
+			reverted[change.Kind][change.ID] = struct{}{}
+		}
+	}
+
+	if len(reverted) > 0 {
+		// Changes that were undone by a later change, e.g. A → B → A, have nothing left to report.
+		return merged.Filter(func(change Change) bool {
+			_, ok := reverted[change.Kind][change.ID]
+			return !ok
+		})
+	}
+	return merged
+}
+
+// merge combines the change with a later change to the same resource. Fields that end up back at their
+// previous value are dropped, and false is returned if the later change undid the earlier one entirely.
+func (c Change) merge(later Change) (Change, bool) {
+	merged := later
+	if c.Type == Added && later.Type == Updated {
+		merged.Type = Added
+	}
+	merged.Severity = ghstatus.MaxSeverity(c.Severity, later.Severity)
+
+	merged.Fields = append([]FieldChange(nil), c.Fields...)
+	for _, field := range later.Fields {
+		found := false
+		for i := range merged.Fields {
+			if merged.Fields[i].Field == field.Field {
+				merged.Fields[i].Current = field.Current
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.Fields = append(merged.Fields, field)
+		}
+	}
+
+	if merged.Type == Updated {
+		var fields []FieldChange
+		for _, field := range merged.Fields {
+			if field.Previous != field.Current {
+				fields = append(fields, field)
+			}
+		}
+		merged.Fields = fields
+		if len(c.Fields) > 0 && len(later.Fields) > 0 && len(fields) == 0 {
+			return merged, false
+		}
+	}
+
+	return merged, true
+}
+
+// indexOfChange returns the index of the change to the resource of the given kind and ID, or -1 if there isn't one.
+func indexOfChange(changes []Change, kind Kind, id string) int {
+	for i, change := range changes {
+		if change.Kind == kind && change.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Empty returns true if the message doesn't contain any changes.
+func (m Message) Empty() bool {
+	return len(m.Changes) == 0
+}
+
+// filterResources returns the resources whose IDs are in the given set.
+func filterResources[T any](resources []T, ids map[string]struct{}, idGetter func(T) string) []T {
+	var filtered []T
+	for _, resource := range resources {
+		if _, ok := ids[idGetter(resource)]; ok {
+			filtered = append(filtered, resource)
+		}
+	}
+	return filtered
+}
+
+// mergeResources returns the resources with those of the later list replacing the ones with the same ID and
+// the rest appended.
+func mergeResources[T any](resources []T, later []T, idGetter func(T) string) []T {
+	merged := append([]T(nil), resources...)
+	for _, resource := range later {
+		found := false
+		for i := range merged {
+			if idGetter(merged[i]) == idGetter(resource) {
+				merged[i] = resource
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, resource)
+		}
+	}
+	return merged
 }
@@ -0,0 +1,113 @@
+package notifiers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// transition describes the given field of a resource. If the field changed from a previous value,
+// this is of the form "previous → current", otherwise it's just the current value.
+func transition(msg notifier.Message, kind notifier.Kind, id string, field string, current string) string {
+	if fieldChange, ok := previousValue(msg, kind, id, field); ok {
+		return notifier.Transition(fieldChange.Previous, fieldChange.Current)
+	}
+	return current
+}
+
+// previousValue returns the change to the given field of a resource if it changed from a previous value.
+func previousValue(msg notifier.Message, kind notifier.Kind, id string, field string) (notifier.FieldChange, bool) {
+	change, ok := msg.Change(kind, id)
+	if !ok {
+		return notifier.FieldChange{}, false
+	}
+
+	fieldChange, ok := change.Field(field)
+	if !ok || fieldChange.Previous == "" {
+		return notifier.FieldChange{}, false
+	}
+
+	return fieldChange, true
+}
+
+// changeType returns the type of change made to the resource, if there is one.
+func changeType(msg notifier.Message, kind notifier.Kind, id string) notifier.ChangeType {
+	change, _ := msg.Change(kind, id)
+	return change.Type
+}
+
+// escalatedAfter returns how long the incident has been unresolved for if the change to it is an escalation.
+func escalatedAfter(msg notifier.Message, id string) (string, bool) {
+	change, ok := msg.Change(notifier.KindIncident, id)
+	if !ok || change.Type != notifier.Escalated {
+		return "", false
+	}
+	field, _ := change.Field(notifier.FieldUnresolvedFor)
+	return field.Current, true
+}
+
+// availability returns the change to the availability of the status page itself, if there is one.
+func availability(msg notifier.Message) (notifier.Change, bool) {
+	change, ok := msg.Change(notifier.KindSource, "")
+	if !ok || (change.Type != notifier.Unavailable && change.Type != notifier.Recovered) {
+		return notifier.Change{}, false
+	}
+	return change, true
+}
+
+// fieldValue returns the current value of the given field of the change.
+func fieldValue(change notifier.Change, field string) string {
+	fieldChange, _ := change.Field(field)
+	return fieldChange.Current
+}
+
+// removed returns the changes of the given kind for resources that are no longer listed and
+// whose final state couldn't be confirmed.
+func removed(msg notifier.Message, kind notifier.Kind) []notifier.Change {
+	var changes []notifier.Change
+	for _, change := range msg.Changes {
+		if change.Kind == kind && change.Type == notifier.Removed {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// renamed returns the previous name of the resource if it has been renamed.
+func renamed(msg notifier.Message, kind notifier.Kind, id string) (string, bool) {
+	change, ok := msg.Change(kind, id)
+	if !ok {
+		return "", false
+	}
+
+	previous, _, ok := change.Renamed()
+	return previous, ok
+}
+
+// componentName returns the name of the component, including the group it's in if it belongs to one.
+func componentName(msg notifier.Message, component ghstatus.Component) string {
+	if change, ok := msg.Change(notifier.KindComponent, component.ID); ok && change.Group != "" {
+		return fmt.Sprintf("%s (in group %s)", component.Name, change.Group)
+	}
+	return component.Name
+}
+
+// affectedComponents describes the components affected by an incident or scheduled maintenance. If the
+// latest update lists the affected components, their status changes are included.
+func affectedComponents(components []ghstatus.Component, updates []ghstatus.IncidentUpdate) string {
+	if len(updates) > 0 && len(updates[0].AffectedComponents) > 0 {
+		descriptions := make([]string, 0, len(updates[0].AffectedComponents))
+		for _, affected := range updates[0].AffectedComponents {
+			if affected.OldStatus != affected.NewStatus {
+				descriptions = append(descriptions, fmt.Sprintf("%s: %s", affected.Name, notifier.Transition(string(affected.OldStatus), string(affected.NewStatus))))
+			} else {
+				descriptions = append(descriptions, fmt.Sprintf("%s: %s", affected.Name, affected.NewStatus))
+			}
+		}
+		return strings.Join(descriptions, ", ")
+	}
+
+	return strings.Join(ghstatus.ComponentNames(components), ", ")
+}
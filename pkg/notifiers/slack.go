@@ -23,6 +23,9 @@ const (
 	slackBadEmoji  = ":warning:"
 	slackInfoEmoji = ":information_source:"
 
+	// slackMaxBlocks is the most blocks Slack accepts in a single message.
+	slackMaxBlocks = 50
+
 	slackOAuthTokenCfg  = "slack.oauth.token"
 	slackOAuthTokenFlag = "slack-oauth-token"
 	slackOAuthTokenEnv  = "SLACK_OAUTH_TOKEN"
@@ -141,6 +144,7 @@ func (*SlackNotifier) Name() string {
 func (s *SlackNotifier) Notify(ctx context.Context, msg notifier.Message) error {
 	blocks := &slack.Blocks{}
 
+	s.changedAvailability(msg, blocks)
 	s.changedStatus(msg, blocks)
 	s.changedComponents(msg, blocks)
 	s.changedIncidents(msg, blocks)
@@ -151,7 +155,13 @@ func (s *SlackNotifier) Notify(ctx context.Context, msg notifier.Message) error
 		return nil
 	}
 
-	_, _, err := s.client.PostMessageContext(ctx, s.channelID, slack.MsgOptionBlocks(blocks.BlockSet...))
+	if msg.Deferred {
+		blocks.BlockSet = append([]slack.Block{slack.NewContextBlock("deferred", slack.NewTextBlockObject(
+			slack.MarkdownType, "Changes held back during quiet hours:", false, false,
+		))}, blocks.BlockSet...)
+	}
+
+	_, _, err := s.client.PostMessageContext(ctx, s.channelID, slack.MsgOptionBlocks(truncateBlocks(blocks.BlockSet)...))
 	if err != nil {
 		return fmt.Errorf("error posting message: %w", err)
 	}
@@ -161,11 +171,67 @@ func (s *SlackNotifier) Notify(ctx context.Context, msg notifier.Message) error
 	return nil
 }
 
+// truncateBlocks returns the blocks cut down to the most Slack accepts in a message, with a final block
+// saying how many changes were left out. Long digests would otherwise be rejected by Slack.
+func truncateBlocks(blockSet []slack.Block) []slack.Block {
+	if len(blockSet) <= slackMaxBlocks {
+		return blockSet
+	}
+
+	omitted := 0
+	for _, block := range blockSet[slackMaxBlocks-1:] {
+		if block.BlockType() != slack.MBTHeader {
+			omitted++
+		}
+	}
+	truncated := append([]slack.Block(nil), blockSet[:slackMaxBlocks-1]...)
+	return append(truncated, slack.NewContextBlock("truncated", slack.NewTextBlockObject(
+		slack.MarkdownType, fmt.Sprintf("_...and %d more_", omitted), false, false,
+	)))
+}
+
 // Cleanup performs any cleanup steps.
 func (s *SlackNotifier) Cleanup() error {
 	return nil
 }
 
+// slackEmoji returns the emoji to use for the given severity.
+func slackEmoji(severity ghstatus.Severity) string {
+	switch severity {
+	case ghstatus.SeverityNone:
+		return slackGoodEmoji
+	case ghstatus.SeverityMaintenance:
+		return slackInfoEmoji
+	default:
+		return slackBadEmoji
+	}
+}
+
+// changedAvailability updates the message to contain any change to the availability of the status page itself.
+func (s *SlackNotifier) changedAvailability(msg notifier.Message, blocks *slack.Blocks) {
+	change, ok := availability(msg)
+	if !ok {
+		return
+	}
+
+	slackMsgText := fmt.Sprintf("%s %s can't be reached, %s polls in a row failed: %s", slackBadEmoji, pageName(msg),
+		fieldValue(change, notifier.FieldFailures), fieldValue(change, notifier.FieldError))
+	if change.Type == notifier.Recovered {
+		slackMsgText = fmt.Sprintf("%s %s can be reached again after %s", slackGoodEmoji, pageName(msg),
+			fieldValue(change, notifier.FieldUnavailableFor))
+	}
+
+	text := slack.NewSectionBlock(slack.NewTextBlockObject(
+		slack.MarkdownType, slackMsgText, false, false,
+	), nil, nil, slack.SectionBlockOptionBlockID("availability"))
+
+	blocks.BlockSet = append(blocks.BlockSet,
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, pageHeader(msg, "Availability"), false, false)),
+		text)
+
+	s.log.Debug("Availability change being sent to Slack")
+}
+
 // changedStatus updates the message to contain any information about the changed status.
 func (s *SlackNotifier) changedStatus(msg notifier.Message, blocks *slack.Blocks) {
 	status := msg.ChangedStatus
@@ -176,9 +242,11 @@ func (s *SlackNotifier) changedStatus(msg notifier.Message, blocks *slack.Blocks
 	var slackMsgText string
 	switch status.Indicator {
 	case ghstatus.None:
-		slackMsgText = fmt.Sprintf("%s Github reports no outages", slackGoodEmoji)
+		slackMsgText = fmt.Sprintf("%s %s reports no outages", slackGoodEmoji, pageName(msg))
+	case ghstatus.Maintenance:
+		slackMsgText = fmt.Sprintf("%s %s is under maintenance", slackInfoEmoji, pageName(msg))
 	default:
-		slackMsgText = fmt.Sprintf("%s Github is reporting a *%s* outage", slackBadEmoji, status.Indicator)
+		slackMsgText = fmt.Sprintf("%s %s is reporting a *%s* outage", slackBadEmoji, pageName(msg), status.Indicator)
 	}
 
 	text := slack.NewSectionBlock(slack.NewTextBlockObject(
@@ -186,7 +254,7 @@ func (s *SlackNotifier) changedStatus(msg notifier.Message, blocks *slack.Blocks
 	), nil, nil, slack.SectionBlockOptionBlockID("status"))
 
 	blocks.BlockSet = append(blocks.BlockSet,
-		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Status", false, false)),
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, pageHeader(msg, "Status"), false, false)),
 		text)
 
 	s.log.Debug("Status change being sent to Slack")
@@ -199,21 +267,36 @@ func (s *SlackNotifier) changedComponents(msg notifier.Message, blocks *slack.Bl
 	}
 
 	blocks.BlockSet = append(blocks.BlockSet,
-		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Components", false, false)))
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, pageHeader(msg, "Components"), false, false)))
 
 	for _, component := range msg.ChangedComponents {
 		var slackMsgText string
 
-		switch component.Status {
-		case ghstatus.Operational:
-			slackMsgText = fmt.Sprintf("%s %s is operational", slackGoodEmoji, component.Name)
-		default:
-			slackMsgText = fmt.Sprintf("%s %s is reporting %s", slackBadEmoji, component.Name, component.Status)
+		emoji := slackEmoji(component.Status.Severity())
+
+		if statusChange, ok := previousValue(msg, notifier.KindComponent, component.ID, notifier.FieldStatus); ok {
+			slackMsgText = fmt.Sprintf("%s %s: %s: %s", emoji, pageName(msg), componentName(msg, component), notifier.Transition(statusChange.Previous, "*"+statusChange.Current+"*"))
+		} else if component.Status == ghstatus.Operational {
+			slackMsgText = fmt.Sprintf("%s %s: %s is operational", emoji, pageName(msg), componentName(msg, component))
+		} else {
+			slackMsgText = fmt.Sprintf("%s %s: %s is reporting %s", emoji, pageName(msg), componentName(msg, component), component.Status)
+		}
+
+		switch changeType(msg, notifier.KindComponent, component.ID) {
+		case notifier.Unstable:
+			slackMsgText = fmt.Sprintf("%s %s: %s is unstable and flapping between statuses, currently *%s*",
+				slackBadEmoji, pageName(msg), componentName(msg, component), component.Status)
+		case notifier.Stabilized:
+			slackMsgText = fmt.Sprintf("%s %s: %s has stabilized and is *%s*", emoji, pageName(msg), componentName(msg, component), component.Status)
+		}
+
+		if previousName, ok := renamed(msg, notifier.KindComponent, component.ID); ok {
+			slackMsgText += fmt.Sprintf(" (renamed from %s)", previousName)
 		}
 
 		text := slack.NewSectionBlock(slack.NewTextBlockObject(
 			slack.MarkdownType, slackMsgText, false, false,
-		), nil, nil, slack.SectionBlockOptionBlockID(fmt.Sprintf("component-%s", component.Name)))
+		), nil, nil, slack.SectionBlockOptionBlockID(fmt.Sprintf("component-%s", component.ID)))
 
 		blocks.BlockSet = append(blocks.BlockSet, text)
 	}
@@ -223,12 +306,13 @@ func (s *SlackNotifier) changedComponents(msg notifier.Message, blocks *slack.Bl
 
 // changedIncidents updates the message to contain any information about the changed incidents.
 func (s *SlackNotifier) changedIncidents(msg notifier.Message, blocks *slack.Blocks) {
-	if len(msg.ChangedIncidents) == 0 {
+	removedIncidents := removed(msg, notifier.KindIncident)
+	if len(msg.ChangedIncidents) == 0 && len(removedIncidents) == 0 {
 		return
 	}
 
 	blocks.BlockSet = append(blocks.BlockSet,
-		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Incidents", false, false)))
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, pageHeader(msg, "Incidents"), false, false)))
 
 	for _, incident := range msg.ChangedIncidents {
 		var slackMsgText string
@@ -248,7 +332,19 @@ func (s *SlackNotifier) changedIncidents(msg notifier.Message, blocks *slack.Blo
 			slackMsgText = fmt.Sprintf("%s %q has status %s", slackInfoEmoji, incident.Name, incident.Status)
 		}
 
-		slackMsgText += fmt.Sprintf(" (impact %s)", incident.Impact)
+		if after, ok := escalatedAfter(msg, incident.ID); ok {
+			slackMsgText = fmt.Sprintf("%s %q is still unresolved after %s, status %s", slackBadEmoji, incident.Name, after, incident.Status)
+		}
+
+		if impactChange, ok := previousValue(msg, notifier.KindIncident, incident.ID, notifier.FieldImpact); ok {
+			slackMsgText += fmt.Sprintf(" (impact changed %s)", notifier.Transition(impactChange.Previous, "*"+impactChange.Current+"*"))
+		} else {
+			slackMsgText += fmt.Sprintf(" (impact %s)", incident.Impact)
+		}
+
+		if affected := affectedComponents(incident.Components, incident.IncidentUpdates); affected != "" {
+			slackMsgText += fmt.Sprintf(", affecting %s", affected)
+		}
 
 		if len(incident.IncidentUpdates) > 0 {
 			slackMsgText += fmt.Sprintf(": %s", incident.IncidentUpdates[0].Body)
@@ -261,17 +357,28 @@ func (s *SlackNotifier) changedIncidents(msg notifier.Message, blocks *slack.Blo
 		blocks.BlockSet = append(blocks.BlockSet, text)
 	}
 
+	for _, change := range removedIncidents {
+		slackMsgText := fmt.Sprintf("%s %q is no longer listed, its final status couldn't be confirmed", slackInfoEmoji, change.Name)
+
+		text := slack.NewSectionBlock(slack.NewTextBlockObject(
+			slack.MarkdownType, slackMsgText, false, false,
+		), nil, nil, slack.SectionBlockOptionBlockID(fmt.Sprintf("incident-%s", change.ID)))
+
+		blocks.BlockSet = append(blocks.BlockSet, text)
+	}
+
 	s.log.Debug("Incidents change being sent to Slack")
 }
 
 // changedScheduledMaintenances updates the message to contain any information about the changed scheduled maintenances.
 func (s *SlackNotifier) changedScheduledMaintenances(msg notifier.Message, blocks *slack.Blocks) {
-	if len(msg.ChangedScheduledMaintenances) == 0 {
+	removedScheduledMaintenances := removed(msg, notifier.KindScheduledMaintenance)
+	if len(msg.ChangedScheduledMaintenances) == 0 && len(removedScheduledMaintenances) == 0 {
 		return
 	}
 
 	blocks.BlockSet = append(blocks.BlockSet,
-		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, "Scheduled Maintenances", false, false)))
+		slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, pageHeader(msg, "Scheduled Maintenances"), false, false)))
 
 	for _, scheduledMaintenance := range msg.ChangedScheduledMaintenances {
 		var slackMsgText string
@@ -289,7 +396,12 @@ func (s *SlackNotifier) changedScheduledMaintenances(msg notifier.Message, block
 			slackMsgText = fmt.Sprintf("%s %q has status %s", slackInfoEmoji, scheduledMaintenance.Name, scheduledMaintenance.Status)
 		}
 
-		slackMsgText += fmt.Sprintf(" (expected impact %s)", scheduledMaintenance.Impact)
+		slackMsgText += fmt.Sprintf(" (expected impact %s, scheduled for %s until %s)",
+			scheduledMaintenance.Impact, scheduledMaintenance.ScheduledFor, scheduledMaintenance.ScheduledUntil)
+
+		if affected := affectedComponents(scheduledMaintenance.Components, scheduledMaintenance.IncidentUpdates); affected != "" {
+			slackMsgText += fmt.Sprintf(", affecting %s", affected)
+		}
 
 		text := slack.NewSectionBlock(slack.NewTextBlockObject(
 			slack.PlainTextType, slackMsgText, false, false,
@@ -298,5 +410,28 @@ func (s *SlackNotifier) changedScheduledMaintenances(msg notifier.Message, block
 		blocks.BlockSet = append(blocks.BlockSet, text)
 	}
 
+	for _, change := range removedScheduledMaintenances {
+		slackMsgText := fmt.Sprintf("%s %q is no longer listed, its final status couldn't be confirmed", slackInfoEmoji, change.Name)
+
+		text := slack.NewSectionBlock(slack.NewTextBlockObject(
+			slack.PlainTextType, slackMsgText, false, false,
+		), nil, nil, slack.SectionBlockOptionBlockID(fmt.Sprintf("scheduled-maintenance-%s", change.ID)))
+
+		blocks.BlockSet = append(blocks.BlockSet, text)
+	}
+
 	s.log.Debug("Scheduled maintenances change being sent to Slack")
 }
+
+// pageName returns the name of the page the message is for.
+func pageName(msg notifier.Message) string {
+	if msg.Page == "" {
+		return ghstatus.GithubPageName
+	}
+	return msg.Page
+}
+
+// pageHeader returns a header for a section of the message prefixed with the page name.
+func pageHeader(msg notifier.Message, section string) string {
+	return fmt.Sprintf("%s %s", pageName(msg), section)
+}
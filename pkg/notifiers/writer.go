@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/mdwn/ghstatus/pkg/notifier"
 )
@@ -16,6 +17,8 @@ const (
 // meant to be used by other notifiers and not directly, so it is
 // not registered with the notifier registry.
 type WriterNotifier struct {
+	// mu keeps the lines of messages for different pages from being interleaved.
+	mu     sync.Mutex
 	writer io.Writer
 }
 
@@ -33,8 +36,36 @@ func (*WriterNotifier) Name() string {
 
 // Notify will notify an underlying system with the given message.
 func (w *WriterNotifier) Notify(_ context.Context, msg notifier.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prefix := ""
+	if msg.Page != "" {
+		prefix = fmt.Sprintf("[%s] ", msg.Page)
+	}
+
+	if msg.Deferred {
+		_, err := fmt.Fprintf(w.writer, "%sChanges held back during quiet hours:\n", prefix)
+		if err != nil {
+			return fmt.Errorf("error while writing header: %w", err)
+		}
+	}
+
+	if change, ok := availability(msg); ok {
+		line := fmt.Sprintf("%sStatus page unavailable after %s failed polls: %s\n",
+			prefix, fieldValue(change, notifier.FieldFailures), fieldValue(change, notifier.FieldError))
+		if change.Type == notifier.Recovered {
+			line = fmt.Sprintf("%sStatus page available again after %s\n", prefix, fieldValue(change, notifier.FieldUnavailableFor))
+		}
+		_, err := io.WriteString(w.writer, line)
+		if err != nil {
+			return fmt.Errorf("error while writing availability: %w", err)
+		}
+	}
+
 	if msg.ChangedStatus != nil {
-		_, err := fmt.Fprintf(w.writer, "Status: %s (%s)\n", msg.ChangedStatus.Indicator, msg.ChangedStatus.Description)
+		indicator := transition(msg, notifier.KindStatus, "", notifier.FieldIndicator, string(msg.ChangedStatus.Indicator))
+		_, err := fmt.Fprintf(w.writer, "%sStatus: %s (%s)\n", prefix, indicator, msg.ChangedStatus.Description)
 		if err != nil {
 			return fmt.Errorf("error while writing status: %w", err)
 		}
@@ -42,7 +73,23 @@ func (w *WriterNotifier) Notify(_ context.Context, msg notifier.Message) error {
 
 	if len(msg.ChangedComponents) > 0 {
 		for _, component := range msg.ChangedComponents {
-			_, err := fmt.Fprintf(w.writer, "Component %s: %s, updated at: %s\n", component.Name, component.Status, component.UpdatedAt)
+			if previousName, ok := renamed(msg, notifier.KindComponent, component.ID); ok {
+				_, err := fmt.Fprintf(w.writer, "%sComponent %s renamed to %s\n", prefix, previousName, component.Name)
+				if err != nil {
+					return fmt.Errorf("error while writing component: %w", err)
+				}
+			}
+
+			status := transition(msg, notifier.KindComponent, component.ID, notifier.FieldStatus, string(component.Status))
+			line := fmt.Sprintf("%sComponent %s: %s, updated at: %s\n", prefix, componentName(msg, component), status, component.UpdatedAt)
+			switch changeType(msg, notifier.KindComponent, component.ID) {
+			case notifier.Unstable:
+				line = fmt.Sprintf("%sComponent %s is unstable and flapping between statuses, currently %s\n",
+					prefix, componentName(msg, component), component.Status)
+			case notifier.Stabilized:
+				line = fmt.Sprintf("%sComponent %s has stabilized: %s\n", prefix, componentName(msg, component), status)
+			}
+			_, err := io.WriteString(w.writer, line)
 			if err != nil {
 				return fmt.Errorf("error while writing component: %w", err)
 			}
@@ -53,25 +100,54 @@ func (w *WriterNotifier) Notify(_ context.Context, msg notifier.Message) error {
 		for _, incident := range msg.ChangedIncidents {
 			lastUpdate := ""
 			if len(incident.IncidentUpdates) > 0 {
-				lastUpdate = incident.IncidentUpdates[0].Body
+				lastUpdate = fmt.Sprintf(": %s", incident.IncidentUpdates[0].Body)
+			}
+			status := transition(msg, notifier.KindIncident, incident.ID, notifier.FieldStatus, string(incident.Status))
+			impact := transition(msg, notifier.KindIncident, incident.ID, notifier.FieldImpact, string(incident.Impact))
+			if affected := affectedComponents(incident.Components, incident.IncidentUpdates); affected != "" {
+				impact += fmt.Sprintf(", affects %s", affected)
 			}
-			_, err := fmt.Fprintf(w.writer, "Incident %s: %s, updated at: %s%s\n", incident.Name, incident.Status, incident.UpdatedAt, lastUpdate)
+			line := fmt.Sprintf("%sIncident %s: %s (impact %s), updated at: %s%s\n",
+				prefix, incident.Name, status, impact, incident.UpdatedAt, lastUpdate)
+			if after, ok := escalatedAfter(msg, incident.ID); ok {
+				line = fmt.Sprintf("%sIncident %s still unresolved after %s: %s (impact %s), updated at: %s%s\n",
+					prefix, incident.Name, after, status, impact, incident.UpdatedAt, lastUpdate)
+			}
+			_, err := io.WriteString(w.writer, line)
 			if err != nil {
 				return fmt.Errorf("error while writing status: %w", err)
 			}
 		}
 	}
 
+	for _, change := range removed(msg, notifier.KindIncident) {
+		_, err := fmt.Fprintf(w.writer, "%sIncident %s: no longer listed\n", prefix, change.Name)
+		if err != nil {
+			return fmt.Errorf("error while writing status: %w", err)
+		}
+	}
+
 	if len(msg.ChangedScheduledMaintenances) > 0 {
 		for _, scheduledMaintenance := range msg.ChangedScheduledMaintenances {
-			_, err := fmt.Fprintf(w.writer, "Scheduled maintenance %s: %s, updated at: %s\n",
-				scheduledMaintenance.Name, scheduledMaintenance.Status, scheduledMaintenance.UpdatedAt)
+			status := transition(msg, notifier.KindScheduledMaintenance, scheduledMaintenance.ID, notifier.FieldStatus, string(scheduledMaintenance.Status))
+			if affected := affectedComponents(scheduledMaintenance.Components, scheduledMaintenance.IncidentUpdates); affected != "" {
+				status += fmt.Sprintf(" (affects %s)", affected)
+			}
+			_, err := fmt.Fprintf(w.writer, "%sScheduled maintenance %s: %s, scheduled for: %s until %s, updated at: %s\n",
+				prefix, scheduledMaintenance.Name, status, scheduledMaintenance.ScheduledFor, scheduledMaintenance.ScheduledUntil, scheduledMaintenance.UpdatedAt)
 			if err != nil {
 				return fmt.Errorf("error while writing status: %w", err)
 			}
 		}
 	}
 
+	for _, change := range removed(msg, notifier.KindScheduledMaintenance) {
+		_, err := fmt.Fprintf(w.writer, "%sScheduled maintenance %s: no longer listed\n", prefix, change.Name)
+		if err != nil {
+			return fmt.Errorf("error while writing status: %w", err)
+		}
+	}
+
 	return nil
 }
 
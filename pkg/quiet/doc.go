@@ -0,0 +1,18 @@
+// Package quiet decides when notifications should be held back.
+//
+// Quiet hours recur on certain days of the week at a given time of day in a given time
+// zone, while mute windows are one-off periods between two points in time. Both apply to
+// every notifier or only to the notifiers they name, and can let changes of at least a
+// given severity through. They're typically declared in the config file:
+//
+//	quiet_hours:
+//	  - notifiers: [pagerduty]
+//	    time_zone: Europe/Berlin
+//	    days: [mon, tue, wed, thu, fri]
+//	    start: "22:00"
+//	    end: "07:00"
+//	    allow_severity: critical
+//	mutes:
+//	  - start: 2026-10-20T10:00:00Z
+//	    end: 2026-10-20T12:00:00Z
+package quiet
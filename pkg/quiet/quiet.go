@@ -0,0 +1,261 @@
+package quiet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+const (
+	// clockFormat is the format of the start and end of quiet hours.
+	clockFormat = "15:04"
+)
+
+// Schedule is a recurring period of quiet hours.
+type Schedule struct {
+	// Name is an optional name for the schedule, used in errors.
+	Name string `mapstructure:"name" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Notifiers are the names of the notifiers the quiet hours apply to. Quiet hours without notifiers
+	// apply to every notifier.
+	Notifiers []string `mapstructure:"notifiers" json:"notifiers,omitempty" yaml:"notifiers,omitempty"`
+
+	// TimeZone is the IANA name of the time zone the quiet hours are in. This defaults to UTC.
+	TimeZone string `mapstructure:"time_zone" json:"time_zone,omitempty" yaml:"time_zone,omitempty"`
+
+	// Days are the days of the week the quiet hours start on, e.g. mon or monday. Quiet hours without
+	// days are observed every day.
+	Days []string `mapstructure:"days" json:"days,omitempty" yaml:"days,omitempty"`
+
+	// Start is the time of day the quiet hours start at, as HH:MM.
+	Start string `mapstructure:"start" json:"start" yaml:"start"`
+
+	// End is the time of day the quiet hours end at, as HH:MM. If this is before the start, the quiet
+	// hours end the next day. If it's the same as the start, the quiet hours last the whole day.
+	End string `mapstructure:"end" json:"end" yaml:"end"`
+
+	// AllowSeverity is the minimum severity of changes that are delivered during the quiet hours anyway.
+	// If this isn't set, every change is held back.
+	AllowSeverity string `mapstructure:"allow_severity" json:"allow_severity,omitempty" yaml:"allow_severity,omitempty"`
+}
+
+// Mute is a one-off mute window.
+type Mute struct {
+	// Name is an optional name for the mute window, used in errors.
+	Name string `mapstructure:"name" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Notifiers are the names of the notifiers that are muted. Mute windows without notifiers mute
+	// every notifier.
+	Notifiers []string `mapstructure:"notifiers" json:"notifiers,omitempty" yaml:"notifiers,omitempty"`
+
+	// Start is when the mute window starts, in RFC 3339 format. If this isn't set, the mute window
+	// starts right away.
+	Start string `mapstructure:"start" json:"start,omitempty" yaml:"start,omitempty"`
+
+	// End is when the mute window ends, in RFC 3339 format.
+	End string `mapstructure:"end" json:"end" yaml:"end"`
+
+	// AllowSeverity is the minimum severity of changes that are delivered during the mute window anyway.
+	// If this isn't set, every change is held back.
+	AllowSeverity string `mapstructure:"allow_severity" json:"allow_severity,omitempty" yaml:"allow_severity,omitempty"`
+}
+
+// window is a compiled schedule or mute window.
+type window struct {
+	notifiers     map[string]struct{}
+	allowSeverity ghstatus.Severity
+	active        func(time.Time) bool
+}
+
+// Windows decides which notifiers are quiet at a given time.
+type Windows struct {
+	windows []window
+}
+
+// New creates new quiet windows from the given quiet hour schedules and mute windows.
+func New(schedules []Schedule, mutes []Mute) (*Windows, error) {
+	w := &Windows{}
+	for i, schedule := range schedules {
+		compiled, err := compileSchedule(schedule)
+		if err != nil {
+			return nil, fmt.Errorf("error in quiet hours %s: %w", nameOrIndex(schedule.Name, i), err)
+		}
+		w.windows = append(w.windows, compiled)
+	}
+	for i, mute := range mutes {
+		compiled, err := compileMute(mute)
+		if err != nil {
+			return nil, fmt.Errorf("error in mute %s: %w", nameOrIndex(mute.Name, i), err)
+		}
+		w.windows = append(w.windows, compiled)
+	}
+	return w, nil
+}
+
+// nameOrIndex returns the name if there is one, or the one-based index otherwise.
+func nameOrIndex(name string, i int) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// compileSchedule parses the quiet hour schedule.
+func compileSchedule(schedule Schedule) (window, error) {
+	compiled, err := newWindow(schedule.Notifiers, schedule.AllowSeverity)
+	if err != nil {
+		return window{}, err
+	}
+
+	location, err := time.LoadLocation(schedule.TimeZone)
+	if err != nil {
+		return window{}, fmt.Errorf("error loading time zone: %w", err)
+	}
+	start, err := parseClock(schedule.Start)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseClock(schedule.End)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid end: %w", err)
+	}
+	days := map[time.Weekday]struct{}{}
+	for _, name := range schedule.Days {
+		day, err := parseWeekday(name)
+		if err != nil {
+			return window{}, err
+		}
+		days[day] = struct{}{}
+	}
+
+	observedOn := func(day time.Weekday) bool {
+		_, ok := days[day]
+		return len(days) == 0 || ok
+	}
+
+	compiled.active = func(now time.Time) bool {
+		local := now.In(location)
+		minute := local.Hour()*60 + local.Minute()
+		today := local.Weekday()
+		yesterday := (today + 6) % 7
+
+		switch {
+		case start == end:
+			return observedOn(today)
+		case start < end:
+			return observedOn(today) && minute >= start && minute < end
+		default:
+			// The quiet hours wrap around midnight, so they're either in the part that started today
+			// or the part that started yesterday.
+			return (observedOn(today) && minute >= start) || (observedOn(yesterday) && minute < end)
+		}
+	}
+
+	return compiled, nil
+}
+
+// compileMute parses the mute window.
+func compileMute(mute Mute) (window, error) {
+	compiled, err := newWindow(mute.Notifiers, mute.AllowSeverity)
+	if err != nil {
+		return window{}, err
+	}
+
+	if mute.End == "" {
+		return window{}, errors.New("no end given")
+	}
+	end, err := time.Parse(time.RFC3339, mute.End)
+	if err != nil {
+		return window{}, fmt.Errorf("invalid end: %w", err)
+	}
+	var start time.Time
+	if mute.Start != "" {
+		if start, err = time.Parse(time.RFC3339, mute.Start); err != nil {
+			return window{}, fmt.Errorf("invalid start: %w", err)
+		}
+	}
+	if !end.After(start) {
+		return window{}, errors.New("end must be after start")
+	}
+
+	compiled.active = func(now time.Time) bool {
+		return !now.Before(start) && now.Before(end)
+	}
+
+	return compiled, nil
+}
+
+// newWindow creates a window for the given notifiers and allowed severity.
+func newWindow(notifiers []string, allowSeverity string) (window, error) {
+	compiled := window{
+		notifiers:     map[string]struct{}{},
+		allowSeverity: ghstatus.SeverityUnknown,
+	}
+	for _, name := range notifiers {
+		compiled.notifiers[name] = struct{}{}
+	}
+	if allowSeverity != "" {
+		var err error
+		if compiled.allowSeverity, err = ghstatus.ParseSeverity(allowSeverity); err != nil {
+			return window{}, err
+		}
+	}
+	return compiled, nil
+}
+
+// appliesTo returns true if the window applies to the given notifier.
+func (w window) appliesTo(notifier string) bool {
+	_, ok := w.notifiers[notifier]
+	return len(w.notifiers) == 0 || ok
+}
+
+// holds returns true if the window holds back the change.
+func (w window) holds(change notifier.Change) bool {
+	return w.allowSeverity == ghstatus.SeverityUnknown || !change.Severity.AtLeast(w.allowSeverity)
+}
+
+// Active returns true if the given notifier is in quiet hours or muted at the given time.
+func (w *Windows) Active(notifier string, now time.Time) bool {
+	for _, window := range w.windows {
+		if window.appliesTo(notifier) && window.active(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Holds returns true if the change should be held back from the given notifier at the given time. A change
+// is held back if any active window applying to the notifier doesn't allow changes as severe as it through.
+// Changes of unknown severity are always held back during a window.
+func (w *Windows) Holds(notifier string, now time.Time, change notifier.Change) bool {
+	for _, window := range w.windows {
+		if window.appliesTo(notifier) && window.active(now) && window.holds(change) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock returns the minute of the day of the given HH:MM time.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse(clockFormat, clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseWeekday parses a full or abbreviated, case-insensitive day of the week.
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if strings.EqualFold(name, full) || strings.EqualFold(name, full[:3]) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized day %s", name)
+}
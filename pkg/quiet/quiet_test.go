@@ -0,0 +1,88 @@
+package quiet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHours(t *testing.T) {
+	windows, err := New([]Schedule{
+		{Notifiers: []string{"pager"}, TimeZone: "America/New_York", Days: []string{"fri", "Saturday"}, Start: "22:00", End: "07:00"},
+		{Start: "12:00", End: "13:00", AllowSeverity: "major"},
+	}, nil)
+	require.NoError(t, err)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		notifier string
+		now      time.Time
+		active   bool
+	}{
+		{name: "friday night", notifier: "pager", now: time.Date(2026, 10, 16, 23, 0, 0, 0, newYork), active: true},
+		{name: "early saturday", notifier: "pager", now: time.Date(2026, 10, 17, 6, 59, 0, 0, newYork), active: true},
+		{name: "saturday morning", notifier: "pager", now: time.Date(2026, 10, 17, 7, 0, 0, 0, newYork), active: false},
+		{name: "early sunday", notifier: "pager", now: time.Date(2026, 10, 18, 3, 0, 0, 0, newYork), active: true},
+		{name: "early monday", notifier: "pager", now: time.Date(2026, 10, 19, 3, 0, 0, 0, newYork), active: false},
+		{name: "friday night in UTC", notifier: "pager", now: time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC), active: true},
+		{name: "other notifier", notifier: "slack", now: time.Date(2026, 10, 16, 23, 0, 0, 0, newYork), active: false},
+		{name: "lunch", notifier: "slack", now: time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC), active: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.active, windows.Active(test.notifier, test.now))
+		})
+	}
+}
+
+func TestHolds(t *testing.T) {
+	windows, err := New(nil, []Mute{
+		{Start: "2026-10-20T10:00:00Z", End: "2026-10-20T12:00:00Z", AllowSeverity: "critical"},
+		{Notifiers: []string{"pager"}, End: "2026-10-20T11:00:00Z"},
+	})
+	require.NoError(t, err)
+
+	minor := notifier.Change{Kind: notifier.KindComponent, Severity: ghstatus.SeverityMinor}
+	critical := notifier.Change{Kind: notifier.KindComponent, Severity: ghstatus.SeverityCritical}
+	unknown := notifier.Change{Kind: notifier.KindComponent, Severity: ghstatus.SeverityUnknown}
+
+	during := time.Date(2026, 10, 20, 10, 30, 0, 0, time.UTC)
+	require.True(t, windows.Holds("slack", during, minor))
+	require.False(t, windows.Holds("slack", during, critical))
+	require.True(t, windows.Holds("slack", during, unknown))
+
+	// The most restrictive active window wins.
+	require.True(t, windows.Holds("pager", during, critical))
+	require.False(t, windows.Holds("pager", time.Date(2026, 10, 20, 11, 30, 0, 0, time.UTC), critical))
+
+	after := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+	require.False(t, windows.Active("slack", after))
+	require.False(t, windows.Holds("slack", after, minor))
+}
+
+func TestNewValidatesWindows(t *testing.T) {
+	_, err := New([]Schedule{{Name: "nights", Start: "22:00"}}, nil)
+	require.ErrorContains(t, err, "quiet hours nights")
+
+	_, err = New([]Schedule{{Start: "22:00", End: "07:00", TimeZone: "Nowhere/Special"}}, nil)
+	require.ErrorContains(t, err, "quiet hours #1")
+
+	_, err = New([]Schedule{{Start: "22:00", End: "07:00", Days: []string{"someday"}}}, nil)
+	require.Error(t, err)
+
+	_, err = New(nil, []Mute{{Start: "2026-10-20T10:00:00Z"}})
+	require.ErrorContains(t, err, "mute #1")
+
+	_, err = New(nil, []Mute{{Start: "2026-10-20T10:00:00Z", End: "2026-10-20T09:00:00Z"}})
+	require.Error(t, err)
+
+	_, err = New(nil, []Mute{{End: "2026-10-20T10:00:00Z", AllowSeverity: "bogus"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,19 @@
+// Package router routes detected changes to specific notifiers.
+//
+// A router holds an ordered list of rules. Each change is checked against the rules in
+// order and is sent to the notifiers of the first rule that matches it. A rule can be
+// marked to continue, in which case matching carries on to later rules as well. Changes
+// that don't match any rule aren't sent anywhere.
+//
+// Rules match on the status page, the kind of resource, the components concerned, the
+// severity of the change and the status transition, and are typically declared in the
+// config file:
+//
+//	routes:
+//	  - notifiers: [pagerduty]
+//	    components: [Actions]
+//	    min_severity: critical
+//	  - notifiers: [file]
+//	    kinds: [scheduled_maintenance]
+//	  - notifiers: [slack]
+package router
@@ -0,0 +1,237 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mdwn/ghstatus/pkg/filter"
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// Rule routes the changes it matches to notifiers. Every condition that is set must match, and
+// unset conditions match everything.
+type Rule struct {
+	// Name is an optional name for the rule, used in errors.
+	Name string `mapstructure:"name" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Notifiers are the names of the notifiers to send matching changes to.
+	Notifiers []string `mapstructure:"notifiers" json:"notifiers" yaml:"notifiers"`
+
+	// Pages are patterns for the names of the status pages to match.
+	Pages []string `mapstructure:"pages" json:"pages,omitempty" yaml:"pages,omitempty"`
+
+	// Kinds are the kinds of resources to match.
+	Kinds []string `mapstructure:"kinds" json:"kinds,omitempty" yaml:"kinds,omitempty"`
+
+	// Components are patterns for the components to match. Components match by their name or the name
+	// of their group, while incidents and scheduled maintenances match by the components they affect.
+	// The overall status never matches a rule with components.
+	Components []string `mapstructure:"components" json:"components,omitempty" yaml:"components,omitempty"`
+
+	// MinSeverity is the minimum severity of changes to match. Changes of unknown severity don't match.
+	MinSeverity string `mapstructure:"min_severity" json:"min_severity,omitempty" yaml:"min_severity,omitempty"`
+
+	// From are patterns for the previous status, or indicator for the overall status, to match. This only
+	// matches changes to the status of existing resources.
+	From []string `mapstructure:"from" json:"from,omitempty" yaml:"from,omitempty"`
+
+	// To are patterns for the current status, or indicator for the overall status, to match. This only
+	// matches changes to the status, including the initial status of added resources.
+	To []string `mapstructure:"to" json:"to,omitempty" yaml:"to,omitempty"`
+
+	// Continue is whether to keep checking later rules after this one matches.
+	Continue bool `mapstructure:"continue" json:"continue,omitempty" yaml:"continue,omitempty"`
+}
+
+// compiledRule is a rule with its patterns parsed.
+type compiledRule struct {
+	notifiers   []string
+	pages       filter.Patterns
+	kinds       map[notifier.Kind]struct{}
+	components  filter.Patterns
+	minSeverity ghstatus.Severity
+	from        filter.Patterns
+	to          filter.Patterns
+	cont        bool
+}
+
+// Router routes changes to notifiers according to an ordered list of rules.
+type Router struct {
+	rules []compiledRule
+}
+
+// New creates a new router from the given rules.
+func New(rules []Rule) (*Router, error) {
+	router := &Router{}
+	for i, rule := range rules {
+		compiled, err := compile(rule)
+		if err != nil {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return nil, fmt.Errorf("error in route %s: %w", name, err)
+		}
+		router.rules = append(router.rules, compiled)
+	}
+	return router, nil
+}
+
+// compile parses the patterns in the rule.
+func compile(rule Rule) (compiledRule, error) {
+	if len(rule.Notifiers) == 0 {
+		return compiledRule{}, errors.New("no notifiers given")
+	}
+
+	compiled := compiledRule{
+		notifiers:   rule.Notifiers,
+		kinds:       map[notifier.Kind]struct{}{},
+		minSeverity: ghstatus.SeverityUnknown,
+		cont:        rule.Continue,
+	}
+
+	var err error
+	if compiled.pages, err = filter.ParsePatterns(rule.Pages); err != nil {
+		return compiledRule{}, err
+	}
+	if compiled.components, err = filter.ParsePatterns(rule.Components); err != nil {
+		return compiledRule{}, err
+	}
+	if compiled.from, err = filter.ParsePatterns(rule.From); err != nil {
+		return compiledRule{}, err
+	}
+	if compiled.to, err = filter.ParsePatterns(rule.To); err != nil {
+		return compiledRule{}, err
+	}
+	for _, kind := range rule.Kinds {
+		k, err := notifier.KindFromString(kind)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		compiled.kinds[k] = struct{}{}
+	}
+	if rule.MinSeverity != "" {
+		if compiled.minSeverity, err = ghstatus.ParseSeverity(rule.MinSeverity); err != nil {
+			return compiledRule{}, err
+		}
+	}
+
+	return compiled, nil
+}
+
+// Notifiers returns the names of every notifier the rules route to.
+func (r *Router) Notifiers() []string {
+	names := map[string]struct{}{}
+	for _, rule := range r.rules {
+		for _, name := range rule.notifiers {
+			names[name] = struct{}{}
+		}
+	}
+
+	notifiers := make([]string, 0, len(names))
+	for name := range names {
+		notifiers = append(notifiers, name)
+	}
+	sort.Strings(notifiers)
+	return notifiers
+}
+
+// Route returns the message to send to each notifier, keyed by the notifier's name. Each message only
+// holds the changes routed to that notifier, and notifiers without any changes are left out.
+func (r *Router) Route(msg notifier.Message) map[string]notifier.Message {
+	routes := map[string]map[changeKey]struct{}{}
+	for _, change := range msg.Changes {
+		for _, name := range r.notifiersFor(msg.Page, change) {
+			if routes[name] == nil {
+				routes[name] = map[changeKey]struct{}{}
+			}
+			routes[name][keyOf(change)] = struct{}{}
+		}
+	}
+
+	messages := map[string]notifier.Message{}
+	for name, changes := range routes {
+		messages[name] = msg.Filter(func(change notifier.Change) bool {
+			_, ok := changes[keyOf(change)]
+			return ok
+		})
+	}
+	return messages
+}
+
+// notifiersFor returns the notifiers the change on the given page is routed to.
+func (r *Router) notifiersFor(page string, change notifier.Change) []string {
+	var notifiers []string
+	for _, rule := range r.rules {
+		if !rule.matches(page, change) {
+			continue
+		}
+		notifiers = append(notifiers, rule.notifiers...)
+		if !rule.cont {
+			break
+		}
+	}
+	return notifiers
+}
+
+// matches returns true if the rule matches the change on the given page.
+func (rule compiledRule) matches(page string, change notifier.Change) bool {
+	if len(rule.pages) > 0 && !rule.pages.MatchAny(page) {
+		return false
+	}
+	if _, ok := rule.kinds[change.Kind]; len(rule.kinds) > 0 && !ok {
+		return false
+	}
+	if !change.Severity.AtLeast(rule.minSeverity) {
+		return false
+	}
+	if len(rule.components) > 0 && !rule.matchesComponents(change) {
+		return false
+	}
+
+	status := statusChange(change)
+	if len(rule.from) > 0 && !rule.from.MatchAny(status.Previous) {
+		return false
+	}
+	if len(rule.to) > 0 && !rule.to.MatchAny(status.Current) {
+		return false
+	}
+
+	return true
+}
+
+// matchesComponents returns true if the change concerns one of the rule's components.
+func (rule compiledRule) matchesComponents(change notifier.Change) bool {
+	switch change.Kind {
+	case notifier.KindComponent:
+		return rule.components.MatchAny(change.Name, change.Group)
+	case notifier.KindIncident, notifier.KindScheduledMaintenance:
+		return rule.components.MatchAny(change.Components...)
+	default:
+		return false
+	}
+}
+
+// statusChange returns the change to the field that describes the state of the resource, which is the
+// indicator for the overall status and the status for everything else.
+func statusChange(change notifier.Change) notifier.FieldChange {
+	field := notifier.FieldStatus
+	if change.Kind == notifier.KindStatus {
+		field = notifier.FieldIndicator
+	}
+	fieldChange, _ := change.Field(field)
+	return fieldChange
+}
+
+// changeKey identifies a change within a message.
+type changeKey struct {
+	kind notifier.Kind
+	id   string
+}
+
+// keyOf returns the key of the change.
+func keyOf(change notifier.Change) changeKey {
+	return changeKey{kind: change.Kind, id: change.ID}
+}
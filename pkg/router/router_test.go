@@ -0,0 +1,110 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute(t *testing.T) {
+	router, err := New([]Rule{
+		{Notifiers: []string{"pager"}, Components: []string{"Actions"}, MinSeverity: "critical", Continue: true},
+		{Notifiers: []string{"file"}, Kinds: []string{"scheduled_maintenance"}},
+		{Notifiers: []string{"recoveries"}, Pages: []string{"npm"}, From: []string{"*_outage"}, To: []string{"operational"}},
+		{Notifiers: []string{"slack"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"file", "pager", "recoveries", "slack"}, router.Notifiers())
+
+	actions := ghstatus.Component{ID: "actions", Name: "Actions", Status: ghstatus.MajorOutage}
+	pages := ghstatus.Component{ID: "pages", Name: "Pages", Status: ghstatus.DegradedPerformance}
+	maintenance := ghstatus.ScheduledMaintenance{ID: "maintenance", Name: "Database upgrade"}
+	msg := notifier.Message{
+		Page:                         ghstatus.GithubPageName,
+		ChangedComponents:            []ghstatus.Component{actions, pages},
+		ChangedScheduledMaintenances: []ghstatus.ScheduledMaintenance{maintenance},
+		Changes: []notifier.Change{
+			{Kind: notifier.KindComponent, ID: "actions", Name: "Actions", Severity: ghstatus.SeverityCritical},
+			{Kind: notifier.KindComponent, ID: "pages", Name: "Pages", Severity: ghstatus.SeverityMinor},
+			{Kind: notifier.KindScheduledMaintenance, ID: "maintenance", Name: "Database upgrade", Severity: ghstatus.SeverityMaintenance},
+		},
+	}
+
+	require.Equal(t, map[string]notifier.Message{
+		"pager": {
+			Page:              ghstatus.GithubPageName,
+			ChangedComponents: []ghstatus.Component{actions},
+			Changes:           msg.Changes[:1],
+		},
+		"file": {
+			Page:                         ghstatus.GithubPageName,
+			ChangedScheduledMaintenances: []ghstatus.ScheduledMaintenance{maintenance},
+			Changes:                      msg.Changes[2:],
+		},
+		"slack": {
+			Page:              ghstatus.GithubPageName,
+			ChangedComponents: []ghstatus.Component{actions, pages},
+			Changes:           msg.Changes[:2],
+		},
+	}, router.Route(msg))
+}
+
+func TestRouteTransitions(t *testing.T) {
+	router, err := New([]Rule{
+		{Notifiers: []string{"recoveries"}, Pages: []string{"npm"}, From: []string{"*_outage"}, To: []string{"operational"}},
+	})
+	require.NoError(t, err)
+
+	recovery := notifier.Change{
+		Kind:   notifier.KindComponent,
+		ID:     "registry",
+		Name:   "Registry",
+		Fields: []notifier.FieldChange{{Field: notifier.FieldStatus, Previous: "partial_outage", Current: "operational"}},
+	}
+	degradation := notifier.Change{
+		Kind:   notifier.KindComponent,
+		ID:     "website",
+		Name:   "Website",
+		Fields: []notifier.FieldChange{{Field: notifier.FieldStatus, Previous: "operational", Current: "degraded_performance"}},
+	}
+
+	routed := router.Route(notifier.Message{Page: "npm", Changes: []notifier.Change{recovery, degradation}})
+	require.Equal(t, []notifier.Change{recovery}, routed["recoveries"].Changes)
+
+	require.Empty(t, router.Route(notifier.Message{Page: "GitHub", Changes: []notifier.Change{recovery}}))
+}
+
+func TestRouteMaintenanceAlias(t *testing.T) {
+	router, err := New([]Rule{
+		{Notifiers: []string{"file"}, Kinds: []string{"maintenance"}},
+		{Notifiers: []string{"slack"}},
+	})
+	require.NoError(t, err)
+
+	maintenance := ghstatus.ScheduledMaintenance{ID: "maintenance", Name: "Database upgrade"}
+	msg := notifier.Message{
+		Page:                         ghstatus.GithubPageName,
+		ChangedScheduledMaintenances: []ghstatus.ScheduledMaintenance{maintenance},
+		Changes: []notifier.Change{
+			{Kind: notifier.KindScheduledMaintenance, ID: "maintenance", Name: "Database upgrade", Severity: ghstatus.SeverityMaintenance},
+		},
+	}
+	require.Equal(t, map[string]notifier.Message{"file": msg}, router.Route(msg))
+}
+
+func TestNewValidatesRules(t *testing.T) {
+	_, err := New([]Rule{{Name: "empty"}})
+	require.ErrorContains(t, err, "route empty")
+
+	_, err = New([]Rule{{Notifiers: []string{"slack"}, Kinds: []string{"bogus"}}})
+	require.ErrorContains(t, err, "route #1")
+	require.ErrorContains(t, err, "valid values are [status, component, incident, scheduled_maintenance, source, maintenance]")
+
+	_, err = New([]Rule{{Notifiers: []string{"slack"}, MinSeverity: "bogus"}})
+	require.Error(t, err)
+
+	_, err = New([]Rule{{Notifiers: []string{"slack"}, Components: []string{"re:("}}})
+	require.Error(t, err)
+}
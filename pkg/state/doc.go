@@ -0,0 +1,7 @@
+// Package state contains stores for persisting monitor state across restarts.
+//
+// The monitor saves the last summary it has seen for each status page, along with
+// the IDs of the incident and scheduled maintenance updates it has already notified
+// about. When the monitor starts back up it loads this state so that it can pick up
+// where it left off instead of re-notifying or missing changes made during the downtime.
+package state
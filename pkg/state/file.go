@@ -0,0 +1,100 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore stores the state of every page as a single JSON file.
+type FileStore struct {
+	path string
+
+	mu sync.Mutex
+}
+
+var _ Store = &FileStore{}
+
+// NewFileStore returns a store that persists state to the JSON file at the given path.
+// The file is created on the first save if it doesn't already exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// Load returns the state of the given page.
+func (f *FileStore) Load(_ context.Context, page string) (PageState, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return PageState{}, false, err
+	}
+
+	state, ok := states[page]
+	return state, ok, nil
+}
+
+// Save saves the state of the given page.
+func (f *FileStore) Save(_ context.Context, page string, state PageState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return err
+	}
+	states[page] = state
+
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("error encoding state: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the state file so that a crash
+	// mid-write never leaves a truncated state file behind.
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error closing state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("error replacing state file: %w", err)
+	}
+
+	return nil
+}
+
+// read reads the state of every page from the state file.
+func (f *FileStore) read() (map[string]PageState, error) {
+	states := map[string]PageState{}
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("error decoding state file: %w", err)
+	}
+
+	return states, nil
+}
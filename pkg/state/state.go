@@ -0,0 +1,61 @@
+package state
+
+import (
+	"context"
+	"time"
+
+	"github.com/mdwn/ghstatus/pkg/ghstatus"
+	"github.com/mdwn/ghstatus/pkg/notifier"
+)
+
+// PageState is the persisted state of a single monitored status page.
+type PageState struct {
+	// LastSummary is the last summary seen for the page.
+	LastSummary ghstatus.SummaryResponse `json:"last_summary"`
+
+	// NotifiedUpdateIDs are the IDs of incident and scheduled maintenance updates
+	// that have already been notified, from oldest to newest.
+	NotifiedUpdateIDs []string `json:"notified_update_ids"`
+
+	// HeldMessages are the changes held back during quiet hours or a mute window, keyed by the name
+	// of the notifier they're held back from.
+	HeldMessages map[string]notifier.Message `json:"held_messages,omitempty"`
+
+	// Digests are the changes collected for notifiers in digest mode, keyed by the name of the notifier.
+	Digests map[string]Digest `json:"digests,omitempty"`
+
+	// Escalations track the unresolved incidents for escalation policies, keyed by incident ID.
+	Escalations map[string]IncidentEscalation `json:"escalations,omitempty"`
+
+	// UnavailableSince is when the page started failing to be polled, if it has been reported as unavailable
+	// and hasn't recovered yet.
+	UnavailableSince time.Time `json:"unavailable_since"`
+}
+
+// Digest is the changes collected for a notifier in digest mode.
+type Digest struct {
+	// Message holds the collected changes.
+	Message notifier.Message `json:"message"`
+
+	// Due is when the digest is to be delivered.
+	Due time.Time `json:"due"`
+}
+
+// IncidentEscalation tracks an unresolved incident for escalation policies.
+type IncidentEscalation struct {
+	// Since is when the incident became unresolved, which is when it was created if that's known.
+	Since time.Time `json:"since"`
+
+	// Escalated are the IDs of the escalation policies that have already fired for the incident.
+	Escalated []string `json:"escalated,omitempty"`
+}
+
+// Store persists page state.
+type Store interface {
+	// Load returns the state of the given page. If no state has been saved for the page,
+	// false is returned.
+	Load(ctx context.Context, page string) (PageState, bool, error)
+
+	// Save saves the state of the given page.
+	Save(ctx context.Context, page string, state PageState) error
+}